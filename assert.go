@@ -1,10 +1,37 @@
 package fsrs
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
+
+// validateCard checks that the memory state of card is consistent.
+func validateCard(card *Card) error {
+	if card.State < Learning || card.State > Relearning {
+		return fmt.Errorf("%w card %d has state %d", ErrInvalidState, card.ID, card.State)
+	}
 
-func assertCard(card *Card) {
 	if !(card.Stability == 0 && card.Difficulty == 0 || card.Difficulty != 0 && card.Stability != 0) {
-		panic(fmt.Sprintf("The Difficulty and Stability of a card are either both zero or both non-zero."))
+		return fmt.Errorf("%w card %d: the Difficulty and Stability of a card are either both zero or both non-zero", ErrInconsistentMemoryState, card.ID)
+	}
+
+	return nil
+}
 
+// validateReview checks that card can be reviewed with rating.
+func validateReview(card *Card, rating Rating) error {
+	if rating < Again || rating > Easy {
+		return fmt.Errorf("%w %d, expected %d to %d", ErrInvalidRating, rating, Again, Easy)
 	}
+
+	return validateCard(card)
+}
+
+// validateReviewDatetime checks that card is not reviewed before its last review.
+func validateReviewDatetime(card *Card, reviewDatetime time.Time) error {
+	if card.LastReview != nil && reviewDatetime.Before(*card.LastReview) {
+		return fmt.Errorf("%w card %d reviewed at %v, last reviewed at %v", ErrReviewBeforeLastReview, card.ID, reviewDatetime, *card.LastReview)
+	}
+
+	return nil
 }
@@ -11,6 +11,8 @@ type Card struct {
 	Difficulty float64    `json:"difficulty"`
 	Due        time.Time  `json:"due"`
 	LastReview *time.Time `json:"last_review"` // Nullable time.Time
+	Reps       int        `json:"reps"`        // Number of reviews
+	Lapses     int        `json:"lapses"`      // Number of Review-state reviews rated Again
 }
 
 func (c *Card) Duplicate() *Card {
@@ -22,6 +24,8 @@ func (c *Card) Duplicate() *Card {
 		Difficulty: c.Difficulty,
 		Due:        c.Due,
 		LastReview: c.LastReview,
+		Reps:       c.Reps,
+		Lapses:     c.Lapses,
 	}
 }
 
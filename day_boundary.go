@@ -0,0 +1,63 @@
+package fsrs
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// WithDayBoundary makes the scheduler count days like Anki, each day starting at hour in the
+// time zone set by WithLocation, or in the time zone of each review datetime without it.
+//
+// Reviews on the same day use the short-term stability, elapsed days are whole days between the
+// days of two reviews, and Review-state cards become due at the start of their due day.
+func WithDayBoundary(hour int) SchedulerOption {
+	return func(s *Scheduler) error {
+		if hour < 0 || hour > 23 {
+			return fmt.Errorf("%w day boundary = %d is out of bounds: [0, 23]", ErrInvalidOption, hour)
+		}
+
+		s.calendarDays = true
+		s.dayBoundary = hour
+
+		return nil
+	}
+}
+
+// dayStart returns the start of the day containing t in calendar days mode.
+func (s *Scheduler) dayStart(t time.Time) time.Time {
+	if s.location != nil {
+		t = t.In(s.location)
+	}
+
+	year, month, day := t.Date()
+	if t.Hour() < s.dayBoundary {
+		day--
+	}
+
+	return time.Date(year, month, day, s.dayBoundary, 0, 0, 0, t.Location())
+}
+
+// elapsedDays returns the days from from to to, whole days between their days in calendar days mode.
+func (s *Scheduler) elapsedDays(from, to time.Time) float64 {
+	if !s.calendarDays {
+		return to.Sub(from).Hours() / 24
+	}
+
+	// compare the dates in UTC so that daylight saving time changes do not matter
+	date := func(t time.Time) time.Time {
+		year, month, day := s.dayStart(t).Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	return math.Round(date(to).Sub(date(from)).Hours() / 24)
+}
+
+// addDays returns the time days days after t, the start of that day in calendar days mode.
+func (s *Scheduler) addDays(t time.Time, days int) time.Time {
+	if !s.calendarDays {
+		return t.Add(time.Duration(days) * 24 * time.Hour)
+	}
+
+	return s.dayStart(t).AddDate(0, 0, days).In(t.Location())
+}
@@ -0,0 +1,68 @@
+package fsrs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDayBoundary(t *testing.T) {
+	location := time.FixedZone("UTC-5", -5*60*60)
+	scheduler := mustNewScheduler(WithEnableFuzzing(false), WithDayBoundary(4), WithLocation(location))
+	hourly := mustNewScheduler(WithEnableFuzzing(false))
+
+	// 03:50 and 04:10 in location are on different days, 04:10 and 03:50 the next morning are not
+	first := time.Date(2024, time.March, 1, 8, 50, 0, 0, time.UTC)
+	assert.Equal(t, 1.0, scheduler.elapsedDays(first, first.Add(20*time.Minute)))
+	assert.Equal(t, 0.0, scheduler.elapsedDays(first.Add(20*time.Minute), first.Add(24*time.Hour)))
+	assert.Equal(t, 2.0, scheduler.elapsedDays(first, first.Add(24*time.Hour+20*time.Minute)))
+
+	// a learning card reviewed again after midnight is not a same-day review
+	card := scheduler.ReviewCard(NewEmptyCard(1), Good, first)
+	card, log := scheduler.ReviewCardWithLog(card, Good, first.Add(20*time.Minute))
+	assert.Equal(t, 1.0, log.ElapsedDays)
+
+	sameDay := hourly.ReviewCard(NewEmptyCard(1), Good, first)
+	sameDay = hourly.ReviewCard(sameDay, Good, first.Add(20*time.Minute))
+	assert.NotEqual(t, sameDay.Stability, card.Stability)
+
+	// Review cards are due at the start of their due day
+	assert.Equal(t, Review, card.State)
+	due := card.Due.In(location)
+	assert.Equal(t, 4, due.Hour())
+	assert.Equal(t, 0, due.Minute())
+	assert.Equal(t, time.UTC, card.Due.Location())
+
+	days := int(scheduler.nextInterval(card.Stability))
+	assert.Equal(t, time.Date(2024, time.March, 1+days, 4, 0, 0, 0, location).UTC(), card.Due)
+
+	_, err := NewScheduler(WithDayBoundary(24))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestDayBoundaryReplayRescheduled(t *testing.T) {
+	location := time.FixedZone("UTC-5", -5*60*60)
+	scheduler := mustNewScheduler(WithEnableFuzzing(false), WithDayBoundary(4), WithLocation(location))
+
+	// rescheduled at 22:30 in location, the card is due at the start of the third day after
+	now := time.Date(2024, time.March, 1, 14, 0, 0, 0, time.UTC)
+	logs := []ReviewLog{
+		{CardID: 1, Rating: Easy, ReviewDatetime: now, Kind: ReviewRated},
+		{CardID: 1, ReviewDatetime: time.Date(2024, time.March, 2, 3, 30, 0, 0, time.UTC), ScheduledDays: 3, Kind: ReviewRescheduled},
+	}
+
+	card, err := scheduler.ReplayCard(1, logs)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 4, 4, 0, 0, 0, location).UTC(), card.Due)
+}
+
+func TestDayBoundarySnapshot(t *testing.T) {
+	scheduler := mustNewScheduler(WithDayBoundary(0), WithLocation(time.UTC))
+
+	restored, err := NewSchedulerFromSnapshot(scheduler.Snapshot())
+	assert.NoError(t, err)
+	assert.Equal(t, scheduler.Snapshot(), restored.Snapshot())
+	assert.True(t, restored.calendarDays)
+	assert.Equal(t, 0, restored.dayBoundary)
+}
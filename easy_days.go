@@ -0,0 +1,90 @@
+package fsrs
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithEasyDays sets the relative workload of each day of the week, indexed by time.Weekday.
+//
+// Fuzzed due dates favor the days with more workload, for example workloads of 0.5 on Saturday,
+// 0 on Sunday and 1 otherwise halve the reviews scheduled on Saturday and skip Sunday whenever
+// the fuzz range has another day. Intervals never leave the fuzz range, Hard, Good and Easy keep
+// their order by narrowing their ranges before a day is picked, and easy days have no effect when
+// fuzzing is disabled.
+func WithEasyDays(workloads [7]float64) SchedulerOption {
+	return func(s *Scheduler) error {
+		var total float64
+		for day, workload := range workloads {
+			if !(workload >= 0 && workload <= 1) {
+				return fmt.Errorf("%w easy days workload of %v = %f is out of bounds: [0, 1]", ErrInvalidOption, time.Weekday(day), workload)
+			}
+			total += workload
+		}
+		if total == 0 {
+			return fmt.Errorf("%w easy days workloads are all zero", ErrInvalidOption)
+		}
+
+		s.easyDays = workloads[:]
+
+		return nil
+	}
+}
+
+// WithLocation sets the time zone of the scheduler's days, defaults to the time zone of each review datetime.
+//
+// It is the time zone in which easy days find the day of the week and, with WithDayBoundary, in
+// which days start, so Review-state due dates fall on the day boundary of location.
+//
+// location must be an IANA time zone loaded by name or a zone created by time.FixedZone, so that
+// Snapshot can restore it; time.Local is rejected as it depends on the host.
+func WithLocation(location *time.Location) SchedulerOption {
+	return func(s *Scheduler) error {
+		if location == nil {
+			return fmt.Errorf("%w location is nil", ErrInvalidOption)
+		}
+		if location == time.Local || location.String() == "Local" {
+			return fmt.Errorf("%w location Local depends on the host, load the time zone by name", ErrInvalidOption)
+		}
+		if _, err := time.LoadLocation(location.String()); err != nil {
+			if _, fixed := zoneOffset(location); !fixed {
+				return fmt.Errorf("%w location %q is neither an IANA time zone nor a fixed zone", ErrInvalidOption, location)
+			}
+		}
+
+		s.location = location
+
+		return nil
+	}
+}
+
+// zoneOffset returns the UTC offset of location in seconds and whether it is the same all year.
+func zoneOffset(location *time.Location) (int, bool) {
+	_, winter := time.Date(2000, time.January, 1, 0, 0, 0, 0, location).Zone()
+	_, summer := time.Date(2000, time.July, 1, 0, 0, 0, 0, location).Zone()
+
+	return winter, winter == summer
+}
+
+// applyEasyDays scales the weights of the intervals from minIvl days on by the workload of their weekday.
+func (s *Scheduler) applyEasyDays(weights []float64, minIvl int, reviewDatetime time.Time) {
+	scaled := make([]float64, len(weights))
+
+	var total float64
+	for i, weight := range weights {
+		due := s.addDays(reviewDatetime, minIvl+i)
+		if s.location != nil {
+			due = due.In(s.location)
+		}
+
+		scaled[i] = weight * s.easyDays[due.Weekday()]
+		total += scaled[i]
+	}
+
+	// every day of the range is an easy day, fall back to the unscaled weights
+	if total == 0 {
+		return
+	}
+
+	copy(weights, scaled)
+}
@@ -0,0 +1,133 @@
+package fsrs
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEasyDays(t *testing.T) {
+	location := time.FixedZone("UTC+10", 10*60*60)
+	workloads := [7]float64{time.Saturday: 0.5, time.Sunday: 0, time.Monday: 1, time.Tuesday: 1, time.Wednesday: 1, time.Thursday: 1, time.Friday: 1}
+	scheduler := mustNewScheduler(WithEasyDays(workloads), WithLocation(location))
+
+	// Friday 20:00 UTC is Saturday 06:00 in location
+	now := time.Date(2024, time.January, 5, 20, 0, 0, 0, time.UTC)
+
+	// the range from 7 to 9 days covers Saturday, Sunday and Monday in location
+	counts := make(map[time.Weekday]int)
+	for i := 0; i < 100; i++ {
+		days := scheduler.balancedDays(7, 9, now, float64(i)/100, nil)
+		counts[now.Add(time.Duration(days)*24*time.Hour).In(location).Weekday()]++
+	}
+	assert.Equal(t, 0, counts[time.Sunday])
+	assert.InDelta(t, 33, counts[time.Saturday], 1)
+	assert.InDelta(t, 67, counts[time.Monday], 1)
+
+	// a range of easy days only is not skipped
+	assert.Equal(t, 8, scheduler.balancedDays(8, 8, now, 0.5, nil))
+
+	// fewer reviews are scheduled on weekends
+	scheduler = mustNewScheduler(WithEasyDays(workloads), WithLocation(location), WithRandomSource(rand.NewSource(1)))
+	due := make(map[time.Weekday]int)
+	for id := int64(1); id <= 200; id++ {
+		card, _ := reviewHistory(scheduler, NewEmptyCard(id), []Rating{Good, Good, Good, Good}, now)
+		due[card.Due.In(location).Weekday()]++
+	}
+	assert.Less(t, due[time.Sunday], due[time.Saturday])
+	assert.Less(t, due[time.Saturday], due[time.Monday])
+
+	_, err := NewScheduler(WithEasyDays([7]float64{}))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = NewScheduler(WithEasyDays([7]float64{1, 1, 1, 1, 1, 1, 2}))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = NewScheduler(WithLocation(nil))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = NewScheduler(WithLocation(time.Local))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestEasyDaysPassingIntervals(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+	workloads := [7]float64{0, 1, 1, 1, 1, 1, 1}
+
+	// keeping Hard < Good < Easy never moves a rating onto a day without workload
+	var checked int
+	for seed := int64(0); seed < 300; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		scheduler := mustNewScheduler(WithEasyDays(workloads), WithRandomSource(rand.NewSource(seed)))
+
+		card := NewEmptyCard(seed)
+		reviewAt := now
+		for i := 0; i < 3+r.Intn(5) || card.State != Review; i++ {
+			card = scheduler.ReviewCard(card, Rating(2+r.Intn(3)), reviewAt)
+			reviewAt = card.Due.Add(time.Duration(r.Intn(72)) * time.Hour)
+		}
+
+		preview := scheduler.Preview(card, reviewAt)
+		for _, rating := range []Rating{Hard, Good, Easy} {
+			item := preview.Item(rating)
+			// shorter intervals are not fuzzed, and Easy has no other day at the maximum interval
+			days := int(math.Round(item.Interval.Hours() / 24))
+			if days < 3 || days == scheduler.maximumInterval {
+				continue
+			}
+
+			checked++
+			assert.NotEqual(t, time.Sunday, item.Card.Due.Weekday(), "seed %d rating %d", seed, rating)
+		}
+	}
+	assert.Greater(t, checked, 500)
+}
+
+func TestEasyDaysSnapshot(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+
+	scheduler := mustNewScheduler(WithEasyDays([7]float64{0, 1, 1, 1, 1, 1, 0.5}), WithLocation(location))
+
+	restored, err := NewSchedulerFromSnapshot(scheduler.Snapshot())
+	assert.NoError(t, err)
+	assert.Equal(t, scheduler.Snapshot(), restored.Snapshot())
+	assert.Equal(t, "Europe/Berlin", restored.Snapshot().Location)
+
+	ss := scheduler.Snapshot()
+	ss.EasyDays = ss.EasyDays[:6]
+	_, err = NewSchedulerFromSnapshot(ss)
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+
+	ss = scheduler.Snapshot()
+	ss.Location = "Nowhere/Atlantis"
+	_, err = NewSchedulerFromSnapshot(ss)
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+}
+
+func TestEasyDaysSnapshotFixedZone(t *testing.T) {
+	location := time.FixedZone("UTC-5", -5*60*60)
+	scheduler := mustNewScheduler(WithEasyDays([7]float64{0, 1, 1, 1, 1, 1, 0.5}), WithLocation(location), WithDayBoundary(4))
+
+	data, err := json.Marshal(scheduler.Snapshot())
+	assert.NoError(t, err)
+
+	var ss SchedulerSnapshot
+	assert.NoError(t, json.Unmarshal(data, &ss))
+	assert.Equal(t, "UTC-5", ss.Location)
+
+	restored, err := NewSchedulerFromSnapshot(&ss)
+	assert.NoError(t, err)
+	assert.Equal(t, scheduler.Snapshot(), restored.Snapshot())
+
+	// the restored scheduler counts days in the same time zone
+	now := time.Date(2024, time.January, 5, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, scheduler.dayStart(now), restored.dayStart(now))
+	assert.Equal(t, -5*60*60, *ss.LocationOffset)
+}
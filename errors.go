@@ -5,5 +5,15 @@ import (
 )
 
 var (
-	ErrInvalidParam = errors.New("Parameters invalid")
+	ErrInvalidParam     = errors.New("Parameters invalid")
+	ErrInvalidOption    = errors.New("Options invalid")
+	ErrInvalidConfig    = errors.New("Config invalid")
+	ErrInvalidReviewLog = errors.New("Review log invalid")
+	ErrInvalidSnapshot  = errors.New("Snapshot invalid")
+	ErrInvalidSM2       = errors.New("SM-2 state invalid")
+
+	ErrInvalidRating           = errors.New("Rating invalid")
+	ErrInvalidState            = errors.New("State invalid")
+	ErrInconsistentMemoryState = errors.New("Memory state inconsistent")
+	ErrReviewBeforeLastReview  = errors.New("Review before last review")
 )
@@ -1,6 +1,11 @@
 package fsrs
 
-import "math"
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
 
 // FuzzRange represents a range for fuzzing intervals in FSRS.
 type FuzzRange struct {
@@ -10,6 +15,9 @@ type FuzzRange struct {
 }
 
 // FuzzRanges represents the fuzzing ranges for FSRS scheduling.
+//
+// Deprecated: FuzzRanges is not read by Scheduler, which defaults to DefaultFuzzConfig. Use
+// WithFuzzConfig instead.
 var FuzzRanges = []FuzzRange{
 	{
 		Start:  2.5,
@@ -27,3 +35,98 @@ var FuzzRanges = []FuzzRange{
 		Factor: 0.05,
 	},
 }
+
+// MarshalJSON implements json.Marshaler, an infinite End is written as null.
+func (fr FuzzRange) MarshalJSON() ([]byte, error) {
+	var end *float64
+	if !math.IsInf(fr.End, 1) {
+		end = &fr.End
+	}
+
+	return json.Marshal(struct {
+		Start  float64  `json:"start"`
+		End    *float64 `json:"end"`
+		Factor float64  `json:"factor"`
+	}{fr.Start, end, fr.Factor})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, a null or missing End is read as positive infinity.
+func (fr *FuzzRange) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Start  float64  `json:"start"`
+		End    *float64 `json:"end"`
+		Factor float64  `json:"factor"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	fr.Start, fr.End, fr.Factor = aux.Start, math.Inf(1), aux.Factor
+	if aux.End != nil {
+		fr.End = *aux.End
+	}
+
+	return nil
+}
+
+// FuzzConfig describes how much intervals are fuzzed.
+type FuzzConfig struct {
+	// Ranges widen the fuzz range of an interval by Factor for every day of it between Start and End.
+	Ranges []FuzzRange `json:"ranges"`
+
+	// Threshold is the number of days below which intervals are not fuzzed.
+	Threshold float64 `json:"threshold"`
+
+	// Minimum is the shortest fuzzed interval in days.
+	Minimum int `json:"minimum"`
+}
+
+// DefaultFuzzConfig returns the fuzz configuration of FSRS.
+func DefaultFuzzConfig() FuzzConfig {
+	return FuzzConfig{
+		Ranges: []FuzzRange{
+			{Start: 2.5, End: 7.0, Factor: 0.15},
+			{Start: 7.0, End: 20.0, Factor: 0.1},
+			{Start: 20.0, End: math.Inf(1), Factor: 0.05},
+		},
+		Threshold: 2.5,
+		Minimum:   2,
+	}
+}
+
+// clone returns a copy of c that does not share its ranges.
+func (c FuzzConfig) clone() FuzzConfig {
+	c.Ranges = append([]FuzzRange(nil), c.Ranges...)
+	return c
+}
+
+// validate checks that the ranges are ordered and do not overlap.
+func (c *FuzzConfig) validate() error {
+	var errorMessages []string
+
+	for i, fr := range c.Ranges {
+		if !(fr.Start >= 0 && fr.Start < fr.End) {
+			errorMessages = append(errorMessages, fmt.Sprintf("ranges[%d] = [%f, %f) is empty or negative", i, fr.Start, fr.End))
+		}
+		if !(fr.Factor >= 0) || math.IsInf(fr.Factor, 1) {
+			errorMessages = append(errorMessages, fmt.Sprintf("ranges[%d] factor = %f must be finite and non-negative", i, fr.Factor))
+		}
+		if i > 0 && fr.Start < c.Ranges[i-1].End {
+			errorMessages = append(errorMessages, fmt.Sprintf("ranges[%d] starts at %f, before the end of ranges[%d] at %f", i, fr.Start, i-1, c.Ranges[i-1].End))
+		}
+	}
+
+	if !(c.Threshold >= 0) || math.IsInf(c.Threshold, 1) {
+		errorMessages = append(errorMessages, fmt.Sprintf("threshold = %f must be finite and non-negative", c.Threshold))
+	}
+
+	if c.Minimum < 1 {
+		errorMessages = append(errorMessages, fmt.Sprintf("minimum = %d must be at least 1 day", c.Minimum))
+	}
+
+	if len(errorMessages) > 0 {
+		return fmt.Errorf("%w fuzz config:\n%s", ErrInvalidOption, strings.Join(errorMessages, "\n"))
+	}
+
+	return nil
+}
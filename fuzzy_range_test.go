@@ -0,0 +1,96 @@
+package fsrs
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzConfig(t *testing.T) {
+	scheduler := mustNewScheduler()
+	assert.Equal(t, DefaultFuzzConfig(), scheduler.fuzz)
+
+	// the deprecated global does not change the default
+	saved := FuzzRanges
+	FuzzRanges = []FuzzRange{{Start: 0, End: 1, Factor: -1}}
+	assert.Equal(t, DefaultFuzzConfig(), mustNewScheduler().fuzz)
+	FuzzRanges = saved
+
+	minIvl, maxIvl := scheduler.getFuzzRange(30)
+	assert.Equal(t, 27, minIvl)
+	assert.Equal(t, 33, maxIvl)
+
+	// the highest draw stays within the fuzz range
+	assert.Equal(t, 33, scheduler.getFuzzedDays(30, time.Now(), func() float64 { return 0.999 }, nil))
+	assert.Equal(t, 27, scheduler.getFuzzedDays(30, time.Now(), func() float64 { return 0 }, nil))
+
+	wide := DefaultFuzzConfig()
+	wide.Ranges[2].Factor = 0.2
+	scheduler = mustNewScheduler(WithFuzzConfig(wide))
+	minIvl, maxIvl = scheduler.getFuzzRange(30)
+	assert.Equal(t, 25, minIvl)
+	assert.Equal(t, 35, maxIvl)
+
+	// the scheduler keeps its own copy of the ranges
+	wide.Ranges[2].Factor = 0
+	minIvl, _ = scheduler.getFuzzRange(30)
+	assert.Equal(t, 25, minIvl)
+
+	scheduler = mustNewScheduler(WithFuzzConfig(FuzzConfig{Threshold: 2.5, Minimum: 4}))
+	minIvl, maxIvl = scheduler.getFuzzRange(3)
+	assert.Equal(t, 4, minIvl)
+	assert.Equal(t, 4, maxIvl)
+
+	// intervals below the threshold are not fuzzed
+	scheduler = mustNewScheduler(
+		WithFuzzConfig(FuzzConfig{Ranges: DefaultFuzzConfig().Ranges, Threshold: 1000, Minimum: 2}),
+		WithRandomSource(rand.NewSource(1)),
+	)
+	unfuzzed := mustNewScheduler(WithEnableFuzzing(false))
+	now := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+	card, _ := reviewHistory(scheduler, NewEmptyCard(1), []Rating{Good, Good, Good, Good}, now)
+	expected, _ := reviewHistory(unfuzzed, NewEmptyCard(1), []Rating{Good, Good, Good, Good}, now)
+	assert.Equal(t, expected, card)
+}
+
+func TestInvalidFuzzConfig(t *testing.T) {
+	for _, config := range []FuzzConfig{
+		{Ranges: []FuzzRange{{Start: 7, End: 2.5, Factor: 0.1}}, Threshold: 2.5, Minimum: 2},
+		{Ranges: []FuzzRange{{Start: 2.5, End: 7, Factor: -0.1}}, Threshold: 2.5, Minimum: 2},
+		{Ranges: []FuzzRange{{Start: 7, End: 20, Factor: 0.1}, {Start: 2.5, End: 7, Factor: 0.15}}, Threshold: 2.5, Minimum: 2},
+		{Ranges: []FuzzRange{{Start: 2.5, End: 10, Factor: 0.1}, {Start: 7, End: 20, Factor: 0.1}}, Threshold: 2.5, Minimum: 2},
+		{Threshold: -1, Minimum: 2},
+		{Threshold: 2.5, Minimum: 0},
+	} {
+		_, err := NewScheduler(WithFuzzConfig(config))
+		assert.ErrorIs(t, err, ErrInvalidOption, "%+v", config)
+	}
+}
+
+func TestFuzzConfigSnapshot(t *testing.T) {
+	config := DefaultFuzzConfig()
+	config.Threshold = 5
+	scheduler := mustNewScheduler(WithFuzzConfig(config))
+
+	data, err := json.Marshal(scheduler.Snapshot())
+	assert.NoError(t, err)
+
+	var ss SchedulerSnapshot
+	assert.NoError(t, json.Unmarshal(data, &ss))
+	assert.Equal(t, scheduler.Snapshot(), &ss)
+	assert.True(t, math.IsInf(ss.FuzzConfig.Ranges[2].End, 1))
+
+	restored, err := NewSchedulerFromSnapshot(&ss)
+	assert.NoError(t, err)
+	assert.Equal(t, config, restored.fuzz)
+
+	// snapshots without a fuzz config use the default one
+	ss.FuzzConfig = nil
+	restored, err = NewSchedulerFromSnapshot(&ss)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultFuzzConfig(), restored.fuzz)
+}
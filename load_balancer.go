@@ -0,0 +1,88 @@
+package fsrs
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DueCounter reports how many cards are already due on the day of due.
+type DueCounter func(due time.Time) int
+
+// WithLoadBalancer picks fuzzed due dates that flatten the daily workload reported by counter.
+//
+// Instead of picking a day of the fuzz range uniformly, days with fewer cards due are favored the
+// same way Anki's load balancer does. The pick still consumes a single fuzz draw, so it stays
+// deterministic under a seeded random source or WithDeterministicFuzz. It has no effect when
+// fuzzing is disabled.
+//
+// counter is called at most once per candidate day of a review or Preview, but it is called
+// concurrently when the Scheduler is shared by several goroutines, so it must be safe for
+// concurrent use.
+func WithLoadBalancer(counter DueCounter) SchedulerOption {
+	return func(s *Scheduler) error {
+		if counter == nil {
+			return fmt.Errorf("%w load balancer due counter is nil", ErrInvalidOption)
+		}
+
+		s.dueCounter = counter
+
+		return nil
+	}
+}
+
+// balancedDays picks an interval between minIvl and maxIvl days with r in [0, 1).
+//
+// With counter each day is weighted by the inverse square of the cards already due on it
+// and the inverse of the interval. With easy days the weights are scaled by the workload of the
+// weekday, and days without workload are skipped unless every day of the range is one.
+func (s *Scheduler) balancedDays(minIvl, maxIvl int, reviewDatetime time.Time, r float64, counter DueCounter) int {
+	weights := make([]float64, 0, maxIvl-minIvl+1)
+	for days := minIvl; days <= maxIvl; days++ {
+		due := s.addDays(reviewDatetime, days)
+
+		weight := 1.0
+		if counter != nil {
+			count := max(counter(due), 0)
+			weight = math.Pow(1/float64(count+1), 2) / float64(days)
+		}
+		weights = append(weights, weight)
+	}
+
+	if s.easyDays != nil {
+		s.applyEasyDays(weights, minIvl, reviewDatetime)
+	}
+
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+
+	target := r * total
+	for i, weight := range weights {
+		if target < weight {
+			return minIvl + i
+		}
+		target -= weight
+	}
+
+	return maxIvl
+}
+
+// dueCounts returns the due counter of the load balancer remembering the count of every day it
+// was asked for, nil without a load balancer. It is not safe for concurrent use.
+func (s *Scheduler) dueCounts() DueCounter {
+	if s.dueCounter == nil {
+		return nil
+	}
+
+	counts := make(map[int64]int)
+	return func(due time.Time) int {
+		count, ok := counts[due.UnixNano()]
+		if !ok {
+			count = s.dueCounter(due)
+			counts[due.UnixNano()] = count
+		}
+		return count
+	}
+}
@@ -0,0 +1,86 @@
+package fsrs
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalancedDays(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+	quietDay := now.Add(12 * 24 * time.Hour)
+
+	scheduler := mustNewScheduler(WithLoadBalancer(func(due time.Time) int {
+		if due.Equal(quietDay) {
+			return 0
+		}
+		return 50
+	}))
+
+	counts := make(map[int]int)
+	for i := 0; i < 100; i++ {
+		counts[scheduler.balancedDays(10, 14, now, float64(i)/100, scheduler.dueCounts())]++
+	}
+	assert.Greater(t, counts[12], 95)
+
+	// without any due cards shorter intervals are slightly favored
+	scheduler = mustNewScheduler(WithLoadBalancer(func(time.Time) int { return 0 }))
+	assert.Equal(t, 10, scheduler.balancedDays(10, 14, now, 0, scheduler.dueCounts()))
+	assert.Equal(t, 14, scheduler.balancedDays(10, 14, now, 0.999, scheduler.dueCounts()))
+	assert.Equal(t, 11, scheduler.balancedDays(10, 14, now, 0.3, scheduler.dueCounts()))
+}
+
+func TestLoadBalancer(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+	ratings := []Rating{Good, Good, Good, Good, Good}
+
+	// every card is due where the fewest cards are due so far
+	schedule := func(seed int64) map[time.Time]int {
+		dues := make(map[time.Time]int)
+		scheduler := mustNewScheduler(
+			WithRandomSource(rand.NewSource(seed)),
+			WithLoadBalancer(func(due time.Time) int { return dues[due] }),
+		)
+		for id := int64(1); id <= 50; id++ {
+			card, _ := reviewHistory(scheduler, NewEmptyCard(id), ratings, now)
+			dues[card.Due]++
+		}
+		return dues
+	}
+
+	assert.Equal(t, schedule(1), schedule(1))
+
+	// the counter is asked about every day at most once per review and per Preview
+	calls := make(map[time.Time]int)
+	scheduler := mustNewScheduler(
+		WithRandomSource(rand.NewSource(1)),
+		WithLoadBalancer(func(due time.Time) int {
+			calls[due]++
+			return 0
+		}),
+	)
+	card, _ := reviewHistory(scheduler, NewEmptyCard(1), ratings, now)
+
+	var days []int
+	for _, review := range []func(){
+		func() { scheduler.ReviewCard(card, Good, card.Due) },
+		func() { scheduler.Preview(card, card.Due) },
+	} {
+		clear(calls)
+		review()
+		assert.NotEmpty(t, calls)
+		for due, n := range calls {
+			assert.Equal(t, 1, n, "due %v", due)
+		}
+		days = append(days, len(calls))
+	}
+
+	// a review only asks about the fuzz range of its rating
+	assert.Less(t, days[0], days[1])
+
+	_, err := NewScheduler(WithLoadBalancer(nil))
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+}
@@ -0,0 +1,169 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/patricksuo/fsrs"
+)
+
+// CalibrationBucket compares the predicted retrievability of the reviews in a range of predictions to their recall rate.
+type CalibrationBucket struct {
+	// Lower and Upper bound the predicted retrievability of the reviews in the bucket, Upper is
+	// included in the last bucket only.
+	Lower float64
+	Upper float64
+
+	// Predicted is the mean predicted retrievability of the reviews, 0 when the bucket is empty.
+	Predicted float64
+
+	// Observed is the recall rate of the reviews, 0 when the bucket is empty.
+	Observed float64
+
+	// Count is the number of reviews in the bucket.
+	Count int
+}
+
+// Calibration is the result of Calibrate.
+type Calibration struct {
+	// Buckets cover the retrievability from 0 to 1 in equal ranges.
+	Buckets []CalibrationBucket
+
+	// ByState holds the buckets of the reviews of cards in each State before the review.
+	ByState map[fsrs.State][]CalibrationBucket
+
+	// ByLastRating holds the buckets of the reviews following each Rating.
+	ByLastRating map[fsrs.Rating][]CalibrationBucket
+}
+
+// Calibrate replays histories through a fsrs.Scheduler with parameters like Evaluate and buckets
+// every review made at least a day after the previous one by its predicted retrievability, the
+// GetCardRetrievability of the card at review time, into bins equal ranges.
+func Calibrate(histories [][]fsrs.ReviewLog, parameters []float64, bins int) (*Calibration, error) {
+	return CalibrateSource(SliceSource(histories), parameters, bins)
+}
+
+// CalibrateSource is like Calibrate but reads the review histories from src in a single pass.
+func CalibrateSource(src HistorySource, parameters []float64, bins int) (*Calibration, error) {
+	if bins < 1 {
+		return nil, fmt.Errorf("%w bins must be positive, got %d", ErrInvalidOption, bins)
+	}
+
+	scheduler, err := newEvaluationScheduler(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	c := newCalibration(bins)
+	err = forEach(context.Background(), src, func(history []fsrs.ReviewLog) error {
+		return predict(scheduler, history, c.add)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.result()
+}
+
+// calibrationTotals sums the predictions and recalls of each bucket.
+type calibrationTotals struct {
+	predicted []float64
+	recalled  []float64
+	count     []int
+}
+
+func newCalibrationTotals(bins int) *calibrationTotals {
+	return &calibrationTotals{
+		predicted: make([]float64, bins),
+		recalled:  make([]float64, bins),
+		count:     make([]int, bins),
+	}
+}
+
+func (t *calibrationTotals) add(p prediction) {
+	bins := len(t.count)
+	i := min(max(int(p.retrievability*float64(bins)), 0), bins-1)
+
+	t.predicted[i] += p.retrievability
+	t.count[i]++
+	if p.recalled {
+		t.recalled[i]++
+	}
+}
+
+func (t *calibrationTotals) buckets() []CalibrationBucket {
+	bins := len(t.count)
+
+	buckets := make([]CalibrationBucket, bins)
+	for i := range buckets {
+		buckets[i] = CalibrationBucket{
+			Lower: float64(i) / float64(bins),
+			Upper: float64(i+1) / float64(bins),
+			Count: t.count[i],
+		}
+		if t.count[i] > 0 {
+			buckets[i].Predicted = t.predicted[i] / float64(t.count[i])
+			buckets[i].Observed = t.recalled[i] / float64(t.count[i])
+		}
+	}
+	return buckets
+}
+
+// calibration accumulates the predictions of Calibrate.
+type calibration struct {
+	bins         int
+	all          *calibrationTotals
+	byState      map[fsrs.State]*calibrationTotals
+	byLastRating map[fsrs.Rating]*calibrationTotals
+}
+
+func newCalibration(bins int) *calibration {
+	return &calibration{
+		bins:         bins,
+		all:          newCalibrationTotals(bins),
+		byState:      make(map[fsrs.State]*calibrationTotals),
+		byLastRating: make(map[fsrs.Rating]*calibrationTotals),
+	}
+}
+
+func (c *calibration) add(p prediction) {
+	c.all.add(p)
+
+	state, ok := c.byState[p.state]
+	if !ok {
+		state = newCalibrationTotals(c.bins)
+		c.byState[p.state] = state
+	}
+	state.add(p)
+
+	rating, ok := c.byLastRating[p.lastRating]
+	if !ok {
+		rating = newCalibrationTotals(c.bins)
+		c.byLastRating[p.lastRating] = rating
+	}
+	rating.add(p)
+}
+
+func (c *calibration) result() (*Calibration, error) {
+	var total int
+	for _, count := range c.all.count {
+		total += count
+	}
+	if total == 0 {
+		return nil, ErrNotEnoughData
+	}
+
+	result := &Calibration{
+		Buckets:      c.all.buckets(),
+		ByState:      make(map[fsrs.State][]CalibrationBucket, len(c.byState)),
+		ByLastRating: make(map[fsrs.Rating][]CalibrationBucket, len(c.byLastRating)),
+	}
+	for state, totals := range c.byState {
+		result.ByState[state] = totals.buckets()
+	}
+	for rating, totals := range c.byLastRating {
+		result.ByLastRating[rating] = totals.buckets()
+	}
+
+	return result, nil
+}
@@ -0,0 +1,58 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/patricksuo/fsrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalibrate(t *testing.T) {
+	histories := syntheticHistories(trueParameters, 400, 10, 1)
+
+	calibration, err := Calibrate(histories, trueParameters, 10)
+	assert.NoError(t, err)
+	assert.Len(t, calibration.Buckets, 10)
+
+	metrics, err := Evaluate(histories, trueParameters)
+	assert.NoError(t, err)
+
+	var total int
+	for i, bucket := range calibration.Buckets {
+		assert.InDelta(t, float64(i)/10, bucket.Lower, 1e-12)
+		assert.InDelta(t, float64(i+1)/10, bucket.Upper, 1e-12)
+		if bucket.Count > 0 {
+			assert.GreaterOrEqual(t, bucket.Predicted, bucket.Lower)
+			assert.LessOrEqual(t, bucket.Predicted, bucket.Upper)
+		}
+		total += bucket.Count
+
+		// the true parameters are well calibrated where there are enough reviews
+		if bucket.Count >= 200 {
+			assert.InDelta(t, bucket.Predicted, bucket.Observed, 0.05, "bucket %d", i)
+		}
+	}
+	assert.Equal(t, metrics.Count, total)
+
+	// every review is in exactly one breakdown of each kind
+	assert.Equal(t, total, breakdownCount(calibration.ByState))
+	assert.Equal(t, total, breakdownCount(calibration.ByLastRating))
+	assert.Contains(t, calibration.ByState, fsrs.Review)
+	assert.Contains(t, calibration.ByLastRating, fsrs.Good)
+
+	_, err = Calibrate(histories, trueParameters, 0)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = Calibrate(nil, trueParameters, 10)
+	assert.ErrorIs(t, err, ErrNotEnoughData)
+}
+
+func breakdownCount[K comparable](breakdown map[K][]CalibrationBucket) int {
+	var n int
+	for _, buckets := range breakdown {
+		for _, bucket := range buckets {
+			n += bucket.Count
+		}
+	}
+	return n
+}
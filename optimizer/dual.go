@@ -0,0 +1,110 @@
+package optimizer
+
+import "math"
+
+// numParameters is the number of FSRS-6 model weights.
+const numParameters = 21
+
+// dual is a forward-mode dual number carrying the partial derivatives of a value
+// with respect to every model weight.
+type dual struct {
+	v float64
+	d [numParameters]float64
+}
+
+func constant(v float64) dual {
+	return dual{v: v}
+}
+
+func variable(v float64, i int) dual {
+	x := dual{v: v}
+	x.d[i] = 1
+	return x
+}
+
+func (a dual) add(b dual) dual {
+	r := dual{v: a.v + b.v}
+	for i := range r.d {
+		r.d[i] = a.d[i] + b.d[i]
+	}
+	return r
+}
+
+func (a dual) addc(c float64) dual {
+	a.v += c
+	return a
+}
+
+func (a dual) sub(b dual) dual {
+	r := dual{v: a.v - b.v}
+	for i := range r.d {
+		r.d[i] = a.d[i] - b.d[i]
+	}
+	return r
+}
+
+func (a dual) neg() dual {
+	return a.mulc(-1)
+}
+
+func (a dual) mul(b dual) dual {
+	r := dual{v: a.v * b.v}
+	for i := range r.d {
+		r.d[i] = a.d[i]*b.v + b.d[i]*a.v
+	}
+	return r
+}
+
+func (a dual) mulc(c float64) dual {
+	r := dual{v: a.v * c}
+	for i := range r.d {
+		r.d[i] = a.d[i] * c
+	}
+	return r
+}
+
+func (a dual) div(b dual) dual {
+	r := dual{v: a.v / b.v}
+	b2 := b.v * b.v
+	for i := range r.d {
+		r.d[i] = (a.d[i]*b.v - a.v*b.d[i]) / b2
+	}
+	return r
+}
+
+// chain returns f(a) given f(a.v) = v and f'(a.v) = dv.
+func (a dual) chain(v, dv float64) dual {
+	r := dual{v: v}
+	for i := range r.d {
+		r.d[i] = a.d[i] * dv
+	}
+	return r
+}
+
+func dexp(a dual) dual {
+	v := math.Exp(a.v)
+	return a.chain(v, v)
+}
+
+func dlog(a dual) dual {
+	return a.chain(math.Log(a.v), 1/a.v)
+}
+
+// dpow returns a**b for a > 0.
+func dpow(a, b dual) dual {
+	return dexp(b.mul(dlog(a)))
+}
+
+func dmin(a, b dual) dual {
+	if b.v < a.v {
+		return b
+	}
+	return a
+}
+
+func dmax(a, b dual) dual {
+	if b.v > a.v {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,12 @@
+package optimizer
+
+import (
+	"errors"
+)
+
+var (
+	ErrInvalidHistory = errors.New("Review history invalid")
+	ErrNotEnoughData  = errors.New("Not enough reviews to optimize")
+	ErrInvalidOption  = errors.New("Optimizer options invalid")
+	ErrNotResettable  = errors.New("History source cannot be reset")
+)
@@ -0,0 +1,327 @@
+package optimizer
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/patricksuo/fsrs"
+)
+
+// Metrics measures how well a parameter set predicts the recalls of review histories.
+type Metrics struct {
+	// LogLoss is the mean binary cross entropy of the predicted retrievability.
+	LogLoss float64
+
+	// RMSEBins is the RMSE(bins) metric of the FSRS benchmark: the root mean squared difference
+	// between the mean predicted retrievability and the recall rate of reviews binned by elapsed
+	// days, number of previous reviews and number of previous lapses, weighted by bin size.
+	RMSEBins float64
+
+	// AUC is the area under the ROC curve of the predicted retrievability, NaN when every review
+	// was recalled or every review was forgotten. EvaluateSource approximates it with the
+	// retrievability rounded down to multiples of 1e-4.
+	AUC float64
+
+	// Count is the number of predicted reviews.
+	Count int
+}
+
+// prediction is the retrievability predicted for a review together with the history before it.
+type prediction struct {
+	retrievability float64
+	recalled       bool
+
+	// elapsedDays is the number of days since the previous review.
+	elapsedDays float64
+
+	// reviews and lapses are the numbers of previous ratings and previous Again ratings.
+	reviews int
+	lapses  int
+
+	// state is the state of the card before the review as logged, lastRating the rating of the previous review.
+	state      fsrs.State
+	lastRating fsrs.Rating
+}
+
+// Evaluate replays histories, one time-ordered slice of ReviewLogs per card, through a
+// fsrs.Scheduler with parameters and reports how well its retrievability predicts the recall
+// of every review made at least a day after the previous one.
+//
+// Manual resets start the card over and manual reschedules are ignored, like in Optimize. The
+// metrics of two parameter sets evaluated on the same histories are comparable.
+func Evaluate(histories [][]fsrs.ReviewLog, parameters []float64) (*Metrics, error) {
+	return evaluate(SliceSource(histories), parameters, &evaluation{exact: true})
+}
+
+// EvaluateSource is like Evaluate but reads the review histories from src in a single pass.
+//
+// Its memory does not grow with the number of reviews, so the AUC is approximated by counting
+// the reviews in 10000 equal ranges of retrievability.
+func EvaluateSource(src HistorySource, parameters []float64) (*Metrics, error) {
+	return evaluate(src, parameters, &evaluation{})
+}
+
+func evaluate(src HistorySource, parameters []float64, e *evaluation) (*Metrics, error) {
+	scheduler, err := newEvaluationScheduler(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	err = forEach(context.Background(), src, func(history []fsrs.ReviewLog) error {
+		return predict(scheduler, history, e.add)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return e.metrics()
+}
+
+func newEvaluationScheduler(parameters []float64) (*fsrs.Scheduler, error) {
+	return fsrs.NewScheduler(
+		fsrs.WithParameters(parameters),
+		fsrs.WithEnableFuzzing(false),
+		fsrs.WithLearningSteps(nil),
+		fsrs.WithRelearningSteps(nil),
+	)
+}
+
+// predict folds history through scheduler and calls visit with the prediction of every review
+// made at least a day after the previous one.
+func predict(scheduler *fsrs.Scheduler, history []fsrs.ReviewLog, visit func(prediction)) error {
+	var (
+		card       *fsrs.Card
+		reviews    int
+		lapses     int
+		lastRating fsrs.Rating
+	)
+
+	for _, review := range history {
+		switch review.Kind {
+		case fsrs.ReviewReset:
+			card = nil
+			continue
+		case fsrs.ReviewRescheduled:
+			continue
+		}
+
+		if card == nil {
+			card = &fsrs.Card{ID: review.CardID, State: fsrs.Learning, Due: review.ReviewDatetime}
+		} else if elapsedDays := review.ReviewDatetime.Sub(*card.LastReview).Hours() / 24; elapsedDays >= 1 {
+			visit(prediction{
+				retrievability: scheduler.GetCardRetrievability(card, review.ReviewDatetime),
+				recalled:       review.Rating > fsrs.Again,
+				elapsedDays:    elapsedDays,
+				reviews:        reviews,
+				lapses:         lapses,
+				state:          review.State,
+				lastRating:     lastRating,
+			})
+		}
+
+		var err error
+		if card, _, err = scheduler.TryReviewCard(card, review.Rating, review.ReviewDatetime); err != nil {
+			return err
+		}
+
+		reviews++
+		if review.Rating == fsrs.Again {
+			lapses++
+		}
+		lastRating = review.Rating
+	}
+
+	return nil
+}
+
+// rmseBin identifies the bins of RMSEBins.
+type rmseBin struct {
+	elapsedDays float64
+	reviews     float64
+	lapses      float64
+}
+
+// rmseBinTotal sums the predictions and recalls of a rmseBin.
+type rmseBinTotal struct {
+	predicted float64
+	recalled  float64
+	count     float64
+}
+
+// aucBins is the number of equal ranges of retrievability in which AUC counts the reviews.
+const aucBins = 10000
+
+// aucHistogram counts recalled and forgotten reviews by predicted retrievability.
+type aucHistogram struct {
+	recalled  [aucBins]float64
+	forgotten [aucBins]float64
+}
+
+func (h *aucHistogram) add(retrievability float64, recalled bool) {
+	i := min(max(int(retrievability*aucBins), 0), aucBins-1)
+	if recalled {
+		h.recalled[i]++
+	} else {
+		h.forgotten[i]++
+	}
+}
+
+// auc returns the area under the ROC curve, the probability that a recalled review has a
+// higher retrievability than a forgotten one, counting reviews in the same range as ties of
+// one half.
+func (h *aucHistogram) auc() float64 {
+	var positives, negatives, area float64
+	for i := range h.recalled {
+		area += h.recalled[i] * (negatives + h.forgotten[i]/2)
+		positives += h.recalled[i]
+		negatives += h.forgotten[i]
+	}
+
+	if positives == 0 || negatives == 0 {
+		return math.NaN()
+	}
+
+	return area / (positives * negatives)
+}
+
+// scored is a predicted retrievability and whether the review was recalled.
+type scored struct {
+	retrievability float64
+	recalled       bool
+}
+
+// evaluation accumulates the predictions of Evaluate.
+type evaluation struct {
+	logLoss float64
+	count   int
+	bins    map[rmseBin]*rmseBinTotal
+
+	// exact determines whether to keep every prediction in scores for the exact AUC instead of
+	// counting them in histogram.
+	exact     bool
+	scores    []scored
+	histogram aucHistogram
+}
+
+func (e *evaluation) add(p prediction) {
+	r := min(max(p.retrievability, lossEpsilon), 1-lossEpsilon)
+	if p.recalled {
+		e.logLoss -= math.Log(r)
+	} else {
+		e.logLoss -= math.Log(1 - r)
+	}
+
+	if e.bins == nil {
+		e.bins = make(map[rmseBin]*rmseBinTotal)
+	}
+	key := rmseBin{
+		elapsedDays: benchmarkBin(p.elapsedDays, 2.48, 3.62, 2),
+		reviews:     benchmarkBin(float64(p.reviews), 1.99, 1.89, 0),
+		lapses:      benchmarkBin(float64(p.lapses), 1.65, 1.73, 0),
+	}
+	bin, ok := e.bins[key]
+	if !ok {
+		bin = &rmseBinTotal{}
+		e.bins[key] = bin
+	}
+	bin.predicted += p.retrievability
+	bin.count++
+	if p.recalled {
+		bin.recalled++
+	}
+
+	if e.exact {
+		e.scores = append(e.scores, scored{p.retrievability, p.recalled})
+	} else {
+		e.histogram.add(p.retrievability, p.recalled)
+	}
+	e.count++
+}
+
+func (e *evaluation) metrics() (*Metrics, error) {
+	n := e.count
+	if n == 0 {
+		return nil, ErrNotEnoughData
+	}
+
+	keys := make([]rmseBin, 0, len(e.bins))
+	for key := range e.bins {
+		keys = append(keys, key)
+	}
+	// sum the bins in a fixed order so that the metrics are reproducible
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.elapsedDays != b.elapsedDays {
+			return a.elapsedDays < b.elapsedDays
+		}
+		if a.reviews != b.reviews {
+			return a.reviews < b.reviews
+		}
+		return a.lapses < b.lapses
+	})
+
+	var squaredError float64
+	for _, key := range keys {
+		bin := e.bins[key]
+		diff := (bin.recalled - bin.predicted) / bin.count
+		squaredError += bin.count * diff * diff
+	}
+
+	metrics := &Metrics{
+		LogLoss:  e.logLoss / float64(n),
+		RMSEBins: math.Sqrt(squaredError / float64(n)),
+		AUC:      e.histogram.auc(),
+		Count:    n,
+	}
+	if e.exact {
+		metrics.AUC = auc(e.scores)
+	}
+
+	return metrics, nil
+}
+
+// benchmarkBin returns the bin of x used by the RMSE(bins) metric of the FSRS benchmark,
+// scale * base^floor(log_base(x)) rounded to digits decimals, and 0 for 0.
+func benchmarkBin(x, scale, base float64, digits int) float64 {
+	if x <= 0 {
+		return 0
+	}
+
+	bin := scale * math.Pow(base, math.Floor(math.Log(x)/math.Log(base)))
+	pow := math.Pow(10, float64(digits))
+	return math.Round(bin*pow) / pow
+}
+
+// auc returns the area under the ROC curve of scores, the probability that a recalled review
+// has a higher retrievability than a forgotten one, counting ties as one half.
+func auc(scores []scored) float64 {
+	sorted := append([]scored(nil), scores...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].retrievability < sorted[j].retrievability })
+
+	var positives, rankSum float64
+	for i := 0; i < len(sorted); {
+		var (
+			j           = i
+			tiedRecalls float64
+		)
+		for j < len(sorted) && sorted[j].retrievability == sorted[i].retrievability {
+			if sorted[j].recalled {
+				tiedRecalls++
+			}
+			j++
+		}
+
+		// ranks i+1 to j share their mean rank
+		rankSum += tiedRecalls * float64(i+1+j) / 2
+		positives += tiedRecalls
+		i = j
+	}
+
+	negatives := float64(len(sorted)) - positives
+	if positives == 0 || negatives == 0 {
+		return math.NaN()
+	}
+
+	return (rankSum - positives*(positives+1)/2) / (positives * negatives)
+}
@@ -0,0 +1,82 @@
+package optimizer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/patricksuo/fsrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	histories := syntheticHistories(trueParameters, 400, 10, 1)
+
+	fitted, err := Evaluate(histories, trueParameters)
+	assert.NoError(t, err)
+	defaults, err := Evaluate(histories, fsrs.DefaultParameters)
+	assert.NoError(t, err)
+
+	assert.Equal(t, defaults.Count, fitted.Count)
+	assert.Less(t, fitted.LogLoss, defaults.LogLoss)
+	assert.Less(t, fitted.RMSEBins, defaults.RMSEBins)
+	assert.Greater(t, fitted.AUC, 0.5)
+	assert.LessOrEqual(t, fitted.AUC, 1.0)
+
+	// the approximate AUC of a single pass is close to the exact one
+	approximate, err := EvaluateSource(SliceSource(histories), trueParameters)
+	assert.NoError(t, err)
+	assert.Equal(t, fitted.LogLoss, approximate.LogLoss)
+	assert.InDelta(t, fitted.AUC, approximate.AUC, 1e-3)
+
+	// the scheduler predicts the same retrievability as the optimizer's model
+	assert.InDelta(t, meanLoss(trueParameters, histories), fitted.LogLoss, 1e-9)
+
+	// older parameter versions are evaluated with their own algorithm
+	_, err = Evaluate(histories, trueParameters[:19])
+	assert.NoError(t, err)
+
+	_, err = Evaluate(histories, trueParameters[:20])
+	assert.ErrorIs(t, err, fsrs.ErrInvalidParam)
+
+	_, err = Evaluate(nil, trueParameters)
+	assert.ErrorIs(t, err, ErrNotEnoughData)
+}
+
+func TestAUC(t *testing.T) {
+	assert.Equal(t, 1.0, auc([]scored{{0.1, false}, {0.4, true}, {0.35, false}, {0.8, true}}))
+	assert.Equal(t, 0.0, auc([]scored{{0.9, false}, {0.2, true}}))
+	assert.Equal(t, 0.5, auc([]scored{{0.5, true}, {0.5, false}}))
+	assert.Equal(t, 0.0, auc([]scored{{0.50001, true}, {0.50002, false}}))
+	assert.Equal(t, 0.75, auc([]scored{{0.5, true}, {0.5, false}, {0.9, true}}))
+	assert.True(t, math.IsNaN(auc([]scored{{0.5, true}, {0.7, true}})))
+}
+
+func TestAUCHistogram(t *testing.T) {
+	type score struct {
+		retrievability float64
+		recalled       bool
+	}
+	auc := func(scores ...score) float64 {
+		var h aucHistogram
+		for _, s := range scores {
+			h.add(s.retrievability, s.recalled)
+		}
+		return h.auc()
+	}
+
+	assert.Equal(t, 1.0, auc(score{0.1, false}, score{0.4, true}, score{0.35, false}, score{0.8, true}))
+	assert.Equal(t, 0.0, auc(score{0.9, false}, score{0.2, true}))
+	assert.Equal(t, 0.5, auc(score{0.5, true}, score{0.5, false}))
+	assert.Equal(t, 0.5, auc(score{0.50001, true}, score{0.50002, false}))
+	assert.Equal(t, 0.75, auc(score{0.5, true}, score{0.5, false}, score{0.9, true}))
+	assert.True(t, math.IsNaN(auc(score{0.5, true}, score{0.7, true})))
+}
+
+func TestBenchmarkBin(t *testing.T) {
+	assert.Equal(t, 0.0, benchmarkBin(0, 2.48, 3.62, 2))
+	assert.Equal(t, 2.48, benchmarkBin(1, 2.48, 3.62, 2))
+	assert.Equal(t, 2.48, benchmarkBin(3, 2.48, 3.62, 2))
+	assert.Equal(t, 8.98, benchmarkBin(4, 2.48, 3.62, 2))
+	assert.Equal(t, 2.0, benchmarkBin(1, 1.99, 1.89, 0))
+	assert.Equal(t, 4.0, benchmarkBin(2, 1.99, 1.89, 0))
+}
@@ -0,0 +1,174 @@
+package optimizer
+
+import (
+	"math"
+	"time"
+
+	"github.com/patricksuo/fsrs"
+)
+
+// lossEpsilon keeps predicted retrievability away from 0 and 1 in the log loss.
+const lossEpsilon = 1e-7
+
+// model evaluates the FSRS-6 memory model on dual numbers so that the loss of a
+// review history comes with its gradient with respect to the weights.
+//
+// The formulas mirror the unexported ones used by fsrs.Scheduler.
+type model struct {
+	w      [numParameters]dual
+	decay  dual
+	factor dual
+}
+
+func newModel(parameters []float64) *model {
+	m := &model{}
+	for i := range m.w {
+		m.w[i] = variable(parameters[i], i)
+	}
+
+	m.decay = m.w[20].neg()
+	m.factor = dexp(constant(math.Log(0.9)).div(m.decay)).addc(-1)
+
+	return m
+}
+
+func (m *model) clampStability(stability dual) dual {
+	return dmax(stability, constant(fsrs.StabilityMin))
+}
+
+func (m *model) clampDifficulty(difficulty dual) dual {
+	return dmin(dmax(difficulty, constant(fsrs.MinDifficulty)), constant(fsrs.MaxDifficulty))
+}
+
+func (m *model) retrievability(elapsedDays float64, stability dual) dual {
+	return dpow(m.factor.mulc(elapsedDays).div(stability).addc(1), m.decay)
+}
+
+func (m *model) initialStability(rating fsrs.Rating) dual {
+	return m.clampStability(m.w[rating-1])
+}
+
+func (m *model) initialDifficulty(rating fsrs.Rating) dual {
+	difficulty := m.w[4].sub(dexp(m.w[5].mulc(float64(rating) - 1))).addc(1)
+	return m.clampDifficulty(difficulty)
+}
+
+func (m *model) nextDifficulty(difficulty dual, rating fsrs.Rating) dual {
+	deltaDifficulty := m.w[6].mulc(-(float64(rating) - 3))
+	damped := difficulty.add(difficulty.neg().addc(10).mul(deltaDifficulty).mulc(1.0 / 9.0))
+
+	arg1 := m.initialDifficulty(fsrs.Easy)
+	reverted := m.w[7].mul(arg1).add(m.w[7].neg().addc(1).mul(damped))
+
+	return m.clampDifficulty(reverted)
+}
+
+func (m *model) shortTermStability(stability dual, rating fsrs.Rating) dual {
+	increase := dexp(m.w[17].mul(m.w[18].addc(float64(rating) - 3))).
+		mul(dpow(stability, m.w[19].neg()))
+
+	if rating == fsrs.Good || rating == fsrs.Easy {
+		increase = dmax(increase, constant(1))
+	}
+
+	return m.clampStability(stability.mul(increase))
+}
+
+func (m *model) nextStability(difficulty, stability, retrievability dual, rating fsrs.Rating) dual {
+	var next dual
+	if rating == fsrs.Again {
+		next = m.nextForgetStability(difficulty, stability, retrievability)
+	} else {
+		next = m.nextRecallStability(difficulty, stability, retrievability, rating)
+	}
+
+	return m.clampStability(next)
+}
+
+func (m *model) nextForgetStability(difficulty, stability, retrievability dual) dual {
+	longTerm := m.w[11].
+		mul(dpow(difficulty, m.w[12].neg())).
+		mul(dpow(stability.addc(1), m.w[13]).addc(-1)).
+		mul(dexp(retrievability.neg().addc(1).mul(m.w[14])))
+
+	shortTerm := stability.div(dexp(m.w[17].mul(m.w[18])))
+
+	return dmin(longTerm, shortTerm)
+}
+
+func (m *model) nextRecallStability(difficulty, stability, retrievability dual, rating fsrs.Rating) dual {
+	increase := dexp(m.w[8]).
+		mul(difficulty.neg().addc(11)).
+		mul(dpow(stability, m.w[9].neg())).
+		mul(dexp(retrievability.neg().addc(1).mul(m.w[10])).addc(-1))
+
+	if rating == fsrs.Hard {
+		increase = increase.mul(m.w[15])
+	}
+	if rating == fsrs.Easy {
+		increase = increase.mul(m.w[16])
+	}
+
+	return stability.mul(increase.addc(1))
+}
+
+// cardLoss folds history through the memory model and returns the summed log loss
+// of the predicted retrievability together with the number of reviews it covers.
+//
+// Like fsrs.Scheduler, reviews less than a day after the previous one update the
+// memory state with the short-term formula; they are not predicted. A manual reset
+// starts the card over, manual reschedules are ignored.
+func (m *model) cardLoss(history []fsrs.ReviewLog) (dual, int) {
+	var (
+		loss       dual
+		count      int
+		stability  dual
+		difficulty dual
+		learned    bool
+		lastReview time.Time
+	)
+
+	for _, review := range history {
+		switch review.Kind {
+		case fsrs.ReviewReset:
+			learned = false
+			continue
+		case fsrs.ReviewRescheduled:
+			continue
+		}
+
+		if !learned {
+			stability = m.initialStability(review.Rating)
+			difficulty = m.initialDifficulty(review.Rating)
+			learned, lastReview = true, review.ReviewDatetime
+			continue
+		}
+
+		elapsedDays := review.ReviewDatetime.Sub(lastReview).Hours() / 24
+		lastReview = review.ReviewDatetime
+
+		if elapsedDays < 1 {
+			stability = m.shortTermStability(stability, review.Rating)
+			difficulty = m.nextDifficulty(difficulty, review.Rating)
+			continue
+		}
+
+		retrievability := m.retrievability(elapsedDays, stability)
+		loss = loss.add(logLoss(retrievability, review.Rating > fsrs.Again))
+		count++
+
+		stability = m.nextStability(difficulty, stability, retrievability, review.Rating)
+		difficulty = m.nextDifficulty(difficulty, review.Rating)
+	}
+
+	return loss, count
+}
+
+// logLoss is the binary cross entropy of predicting recall with probability p.
+func logLoss(p dual, recalled bool) dual {
+	p = dmin(dmax(p, constant(lossEpsilon)), constant(1-lossEpsilon))
+	if recalled {
+		return dlog(p).neg()
+	}
+	return dlog(p.neg().addc(1)).neg()
+}
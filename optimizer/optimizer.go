@@ -0,0 +1,444 @@
+// Package optimizer fits FSRS-6 parameters to review histories.
+package optimizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"time"
+
+	"github.com/patricksuo/fsrs"
+)
+
+// Adam hyper parameters.
+const (
+	adamBeta1   = 0.9
+	adamBeta2   = 0.999
+	adamEpsilon = 1e-8
+)
+
+type optimizer struct {
+	// initialParameters are the weights the optimization starts from.
+	initialParameters []float64
+
+	// epochs is the number of passes over the review histories.
+	epochs int
+
+	// batchSize is the minimum number of predicted reviews per optimization step.
+	batchSize int
+
+	// learningRate is the Adam step size.
+	learningRate float64
+
+	// pretrain determines whether to start from the initial stabilities estimated by Pretrain.
+	pretrain bool
+
+	// freezeInitialStability determines whether to keep the first four weights fixed during the optimization.
+	freezeInitialStability bool
+
+	// workers is the number of goroutines computing the losses of a step.
+	workers int
+
+	// progress is called after every optimization step, nil when not set.
+	progress func(Progress)
+}
+
+// Phase is a pass of the optimization over the review histories.
+type Phase int
+
+const (
+	// PhaseCount counts the predicted reviews.
+	PhaseCount Phase = iota
+
+	// PhasePretrain estimates the initial stabilities, unless disabled with WithPretrain.
+	PhasePretrain
+
+	// PhaseOptimize fits the weights, one pass per epoch.
+	PhaseOptimize
+)
+
+// Progress reports the state of an optimization.
+type Progress struct {
+	// Phase is the current pass over the review histories.
+	Phase Phase
+
+	// Epoch is the current epoch of PhaseOptimize, from 1 to Epochs, and 0 before.
+	Epoch  int
+	Epochs int
+
+	// Reviews is the number of predicted reviews covered in the current pass, out of
+	// TotalReviews, which is 0 while counting or when pretraining alone with Pretrain.
+	Reviews      int
+	TotalReviews int
+
+	// Loss is the mean log loss of the reviews of an optimization step, with the weights before
+	// the step, and 0 outside PhaseOptimize.
+	Loss float64
+}
+
+// Option defines the type for optimizer configuration functions
+type Option func(*optimizer) error
+
+// WithInitialParameters sets the FSRS-6 weights the optimization starts from
+func WithInitialParameters(params []float64) Option {
+	return func(o *optimizer) error {
+		if len(params) != numParameters {
+			return fmt.Errorf("%w initial parameters must be %d FSRS-6 weights, got %d", ErrInvalidOption, numParameters, len(params))
+		}
+		if _, err := fsrs.NewScheduler(fsrs.WithParameters(params)); err != nil {
+			return err
+		}
+
+		o.initialParameters = append([]float64(nil), params...)
+
+		return nil
+	}
+}
+
+// WithEpochs sets the number of passes over the review histories
+func WithEpochs(epochs int) Option {
+	return func(o *optimizer) error {
+		if epochs < 1 {
+			return fmt.Errorf("%w epochs must be positive, got %d", ErrInvalidOption, epochs)
+		}
+
+		o.epochs = epochs
+
+		return nil
+	}
+}
+
+// WithBatchSize sets the minimum number of predicted reviews per optimization step
+func WithBatchSize(size int) Option {
+	return func(o *optimizer) error {
+		if size < 1 {
+			return fmt.Errorf("%w batch size must be positive, got %d", ErrInvalidOption, size)
+		}
+
+		o.batchSize = size
+
+		return nil
+	}
+}
+
+// WithLearningRate sets the Adam step size
+func WithLearningRate(rate float64) Option {
+	return func(o *optimizer) error {
+		if !(rate > 0) || math.IsInf(rate, 1) {
+			return fmt.Errorf("%w learning rate must be positive, got %f", ErrInvalidOption, rate)
+		}
+
+		o.learningRate = rate
+
+		return nil
+	}
+}
+
+// WithPretrain determines whether Optimize starts from the initial stabilities estimated by
+// Pretrain instead of the initial parameters, defaults to true
+func WithPretrain(enable bool) Option {
+	return func(o *optimizer) error {
+		o.pretrain = enable
+		return nil
+	}
+}
+
+// WithFreezeInitialStability determines whether Optimize keeps the first four weights, the
+// initial stabilities, at their starting values, defaults to false
+func WithFreezeInitialStability(enable bool) Option {
+	return func(o *optimizer) error {
+		o.freezeInitialStability = enable
+		return nil
+	}
+}
+
+// WithWorkers sets the number of goroutines computing losses and gradients, defaults to
+// runtime.GOMAXPROCS(0). The result does not depend on it.
+func WithWorkers(workers int) Option {
+	return func(o *optimizer) error {
+		if workers < 1 {
+			return fmt.Errorf("%w workers must be positive, got %d", ErrInvalidOption, workers)
+		}
+
+		o.workers = workers
+
+		return nil
+	}
+}
+
+// WithProgress sets a function called after every optimization step, and every batch size of
+// predicted reviews while counting and pretraining
+func WithProgress(progress func(Progress)) Option {
+	return func(o *optimizer) error {
+		o.progress = progress
+		return nil
+	}
+}
+
+func newOptimizer(options []Option) (*optimizer, error) {
+	o := &optimizer{
+		initialParameters: append([]float64(nil), fsrs.DefaultParameters...),
+		epochs:            5,
+		batchSize:         512,
+		learningRate:      4e-2,
+		pretrain:          true,
+		workers:           runtime.GOMAXPROCS(0),
+	}
+
+	for _, option := range options {
+		if err := option(o); err != nil {
+			return nil, err
+		}
+	}
+
+	return o, nil
+}
+
+// Optimize fits the FSRS-6 weights to histories, one time-ordered slice of ReviewLogs per card.
+//
+// It minimizes the log loss of the retrievability the scheduler predicts for every review
+// made at least a day after the previous one, using Adam on mini-batches of cards and
+// clamping the weights to fsrs.LowerBoundsParameters and fsrs.UpperBoundsParameters after
+// every step. The result is accepted by fsrs.WithParameters.
+//
+// Unless disabled with WithPretrain, the first four weights start from the estimate of Pretrain
+// when any card has a long-term review.
+func Optimize(histories [][]fsrs.ReviewLog, options ...Option) ([]float64, error) {
+	return OptimizeSource(SliceSource(histories), options...)
+}
+
+// OptimizeSource is like Optimize but reads the review histories from src, which must be
+// resettable as it is read once per epoch plus before the optimization.
+func OptimizeSource(src HistorySource, options ...Option) ([]float64, error) {
+	return OptimizeContext(context.Background(), src, options...)
+}
+
+// OptimizeContext is like OptimizeSource but stops with the error of ctx once it is done.
+func OptimizeContext(ctx context.Context, src HistorySource, options ...Option) ([]float64, error) {
+	o, err := newOptimizer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int
+	progress := o.newProgress(PhaseCount, 0)
+	err = forEach(ctx, src, func(history []fsrs.ReviewLog) error {
+		n := countPredicted(history)
+		total += n
+		progress.add(n)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	progress.end()
+	if total == 0 {
+		return nil, ErrNotEnoughData
+	}
+
+	params := append([]float64(nil), o.initialParameters...)
+	if o.pretrain {
+		stabilities, err := o.initialStabilities(ctx, src, total)
+		switch {
+		case err == nil:
+			copy(params, stabilities[:])
+		case !errors.Is(err, ErrNotEnoughData):
+			return nil, err
+		}
+	}
+
+	return o.run(ctx, src, params, total)
+}
+
+// run optimizes params over o.epochs passes of src, which has total predicted reviews.
+//
+// Every step covers the cards read until they have at least o.batchSize predicted reviews. The
+// losses of the cards of a step are computed by o.workers goroutines and summed in card order,
+// so the result does not depend on the number of workers.
+func (o *optimizer) run(ctx context.Context, src HistorySource, params []float64, total int) ([]float64, error) {
+	var (
+		m    [numParameters]float64
+		v    [numParameters]float64
+		step int
+	)
+
+	update := func(grad *[numParameters]float64, count int) {
+		step++
+		correction1 := 1 - math.Pow(adamBeta1, float64(step))
+		correction2 := 1 - math.Pow(adamBeta2, float64(step))
+
+		for i := range params {
+			if o.freezeInitialStability && i < 4 {
+				continue
+			}
+
+			g := grad[i] / float64(count)
+			m[i] = adamBeta1*m[i] + (1-adamBeta1)*g
+			v[i] = adamBeta2*v[i] + (1-adamBeta2)*g*g
+
+			params[i] -= o.learningRate * (m[i] / correction1) / (math.Sqrt(v[i]/correction2) + adamEpsilon)
+			params[i] = min(max(params[i], fsrs.LowerBoundsParameters[i]), fsrs.UpperBoundsParameters[i])
+		}
+	}
+
+	for epoch := 1; epoch <= o.epochs; epoch++ {
+		var (
+			batch    [][]fsrs.ReviewLog
+			count    int
+			reviewed int
+		)
+
+		flush := func() {
+			loss := o.batchLoss(newModel(params), batch)
+			update(&loss.d, count)
+
+			reviewed += count
+			if o.progress != nil {
+				o.progress(Progress{
+					Phase:        PhaseOptimize,
+					Epoch:        epoch,
+					Epochs:       o.epochs,
+					Reviews:      reviewed,
+					TotalReviews: total,
+					Loss:         loss.v / float64(count),
+				})
+			}
+
+			batch, count = batch[:0], 0
+		}
+
+		err := forEach(ctx, src, func(history []fsrs.ReviewLog) error {
+			n := countPredicted(history)
+			if n == 0 {
+				return nil
+			}
+
+			batch = append(batch, history)
+			count += n
+
+			if count >= o.batchSize {
+				flush()
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if count > 0 {
+			flush()
+		}
+	}
+
+	if _, err := fsrs.NewScheduler(fsrs.WithParameters(params)); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// passProgress reports the progress of a pass over the review histories other than PhaseOptimize.
+type passProgress struct {
+	o       *optimizer
+	phase   Phase
+	total   int
+	reviews int
+
+	// reported is the number of reviews at the last report.
+	reported int
+}
+
+func (o *optimizer) newProgress(phase Phase, total int) *passProgress {
+	return &passProgress{o: o, phase: phase, total: total}
+}
+
+// add counts n more reviews, reporting every batch size of reviews.
+func (p *passProgress) add(n int) {
+	p.reviews += n
+	if p.reviews-p.reported >= p.o.batchSize {
+		p.report()
+	}
+}
+
+// end reports the reviews not reported yet at the end of the pass.
+func (p *passProgress) end() {
+	if p.reviews > p.reported {
+		p.report()
+	}
+}
+
+func (p *passProgress) report() {
+	p.reported = p.reviews
+	if p.o.progress == nil {
+		return
+	}
+
+	total := p.total
+	if p.phase == PhaseCount {
+		total = 0
+	}
+	p.o.progress(Progress{
+		Phase:        p.phase,
+		Epochs:       p.o.epochs,
+		Reviews:      p.reviews,
+		TotalReviews: total,
+	})
+}
+
+// validateHistory checks that history belongs to a single card, is ordered by review time
+// and only contains valid ratings.
+func validateHistory(history []fsrs.ReviewLog) error {
+	for i, review := range history {
+		switch review.Kind {
+		case fsrs.ReviewRated:
+			if review.Rating < fsrs.Again || review.Rating > fsrs.Easy {
+				return fmt.Errorf("%w card %d review %d has rating %d", ErrInvalidHistory, review.CardID, i, review.Rating)
+			}
+		case fsrs.ReviewReset, fsrs.ReviewRescheduled:
+		default:
+			return fmt.Errorf("%w card %d review %d has kind %d", ErrInvalidHistory, review.CardID, i, review.Kind)
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		if review.CardID != history[0].CardID {
+			return fmt.Errorf("%w review %d belongs to card %d, expected card %d", ErrInvalidHistory, i, review.CardID, history[0].CardID)
+		}
+		if review.ReviewDatetime.Before(history[i-1].ReviewDatetime) {
+			return fmt.Errorf("%w card %d review %d is earlier than the previous review", ErrInvalidHistory, review.CardID, i)
+		}
+	}
+
+	return nil
+}
+
+// countPredicted returns the number of reviews of history that contribute to the loss.
+func countPredicted(history []fsrs.ReviewLog) int {
+	var (
+		n          int
+		learned    bool
+		lastReview time.Time
+	)
+
+	for _, review := range history {
+		switch review.Kind {
+		case fsrs.ReviewReset:
+			learned = false
+			continue
+		case fsrs.ReviewRescheduled:
+			continue
+		}
+
+		if learned && review.ReviewDatetime.Sub(lastReview).Hours()/24 >= 1 {
+			n++
+		}
+		learned, lastReview = true, review.ReviewDatetime
+	}
+
+	return n
+}
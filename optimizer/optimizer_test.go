@@ -0,0 +1,169 @@
+package optimizer
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/patricksuo/fsrs"
+	"github.com/stretchr/testify/assert"
+)
+
+var trueParameters = []float64{
+	0.1456, 0.4186, 1.1104, 4.1315, 5.2417, 1.3098, 0.8975, 0.0010,
+	1.5674, 0.0567, 0.9661, 2.0275, 0.1592, 0.2446, 1.5071, 0.2272,
+	2.8755, 1.234, 0.56789, 0.1437, 0.2,
+}
+
+// syntheticHistories reviews cards with a scheduler using parameters, sampling recall from
+// the scheduler's own retrievability.
+func syntheticHistories(parameters []float64, cards, reviews int, seed int64) [][]fsrs.ReviewLog {
+	scheduler, err := fsrs.NewScheduler(
+		fsrs.WithParameters(parameters),
+		fsrs.WithDesiredRetention(0.85),
+		fsrs.WithRandomSource(rand.NewSource(seed)),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	var histories [][]fsrs.ReviewLog
+	for id := 1; id <= cards; id++ {
+		var (
+			card    = fsrs.NewEmptyCard(int64(id))
+			now     = start.Add(time.Duration(r.Intn(24*60)) * time.Minute)
+			history []fsrs.ReviewLog
+			rating  = fsrs.Rating(1 + r.Intn(4))
+		)
+
+		for i := 0; i < reviews; i++ {
+			if i > 0 {
+				// review some cards late or early
+				next := card.Due.Add(time.Duration(r.NormFloat64()*0.3*float64(card.Due.Sub(now))) + time.Hour)
+				if next.Before(now) {
+					next = now.Add(time.Hour)
+				}
+				now = next
+				if r.Float64() < scheduler.GetCardRetrievability(card, now) {
+					rating = []fsrs.Rating{fsrs.Hard, fsrs.Good, fsrs.Good, fsrs.Good, fsrs.Easy}[r.Intn(5)]
+				} else {
+					rating = fsrs.Again
+				}
+			}
+
+			var reviewLog *fsrs.ReviewLog
+			card, reviewLog = scheduler.ReviewCardWithLog(card, rating, now)
+			history = append(history, *reviewLog)
+		}
+
+		histories = append(histories, history)
+	}
+
+	return histories
+}
+
+func meanLoss(parameters []float64, histories [][]fsrs.ReviewLog) float64 {
+	m := newModel(parameters)
+
+	var (
+		loss  float64
+		count int
+	)
+	for _, history := range histories {
+		l, n := m.cardLoss(history)
+		loss += l.v
+		count += n
+	}
+
+	return loss / float64(count)
+}
+
+func TestModelMatchesScheduler(t *testing.T) {
+	scheduler, err := fsrs.NewScheduler(fsrs.WithParameters(trueParameters), fsrs.WithEnableFuzzing(false))
+	assert.NoError(t, err)
+
+	m := newModel(trueParameters)
+	histories := syntheticHistories(trueParameters, 20, 12, 7)
+
+	for _, history := range histories {
+		card := fsrs.NewEmptyCard(history[0].CardID)
+
+		var stability, difficulty dual
+		for i, review := range history {
+			if i == 0 {
+				stability = m.initialStability(review.Rating)
+				difficulty = m.initialDifficulty(review.Rating)
+			} else {
+				elapsedDays := review.ReviewDatetime.Sub(history[i-1].ReviewDatetime).Hours() / 24
+				if elapsedDays < 1 {
+					stability = m.shortTermStability(stability, review.Rating)
+				} else {
+					retrievability := m.retrievability(elapsedDays, stability)
+					assert.InDelta(t, scheduler.GetCardRetrievability(card, review.ReviewDatetime), retrievability.v, 1e-9)
+					stability = m.nextStability(difficulty, stability, retrievability, review.Rating)
+				}
+				difficulty = m.nextDifficulty(difficulty, review.Rating)
+			}
+
+			card = scheduler.ReviewCard(card, review.Rating, review.ReviewDatetime)
+			assert.InEpsilon(t, card.Stability, stability.v, 1e-9)
+			assert.InDelta(t, card.Difficulty, difficulty.v, 1e-9)
+		}
+	}
+}
+
+func TestModelGradient(t *testing.T) {
+	history := syntheticHistories(trueParameters, 1, 10, 3)[0]
+
+	loss, _ := newModel(trueParameters).cardLoss(history)
+
+	const h = 1e-6
+	for i := range trueParameters {
+		shifted := append([]float64(nil), trueParameters...)
+		shifted[i] += h
+		up, _ := newModel(shifted).cardLoss(history)
+		shifted[i] -= 2 * h
+		down, _ := newModel(shifted).cardLoss(history)
+
+		numeric := (up.v - down.v) / (2 * h)
+		assert.InDelta(t, numeric, loss.d[i], 1e-4*max(1, math.Abs(numeric)), "parameters[%d]", i)
+	}
+}
+
+func TestOptimize(t *testing.T) {
+	histories := syntheticHistories(trueParameters, 400, 10, 1)
+
+	params, err := Optimize(histories)
+	assert.NoError(t, err)
+	assert.Len(t, params, len(fsrs.DefaultParameters))
+
+	_, err = fsrs.NewScheduler(fsrs.WithParameters(params))
+	assert.NoError(t, err)
+
+	assert.Less(t, meanLoss(params, histories), meanLoss(fsrs.DefaultParameters, histories))
+}
+
+func TestOptimizeInvalidHistory(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	_, err := Optimize(nil)
+	assert.ErrorIs(t, err, ErrNotEnoughData)
+
+	_, err = Optimize([][]fsrs.ReviewLog{{
+		{CardID: 1, Rating: fsrs.Good, ReviewDatetime: now},
+		{CardID: 1, Rating: fsrs.Good, ReviewDatetime: now.Add(-time.Hour)},
+	}})
+	assert.ErrorIs(t, err, ErrInvalidHistory)
+
+	_, err = Optimize([][]fsrs.ReviewLog{{
+		{CardID: 1, Rating: 5, ReviewDatetime: now},
+	}})
+	assert.ErrorIs(t, err, ErrInvalidHistory)
+
+	_, err = Optimize(syntheticHistories(trueParameters, 1, 3, 1), WithEpochs(0))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
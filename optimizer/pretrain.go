@@ -0,0 +1,302 @@
+package optimizer
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/patricksuo/fsrs"
+)
+
+const (
+	// pretrainPriorReviews is the number of pseudo reviews predicted by the initial parameters
+	// added to the reviews of every first rating, so that ratings with few samples stay close
+	// to the initial stabilities.
+	pretrainPriorReviews = 10
+
+	// pretrainGridSize is the number of stabilities tried before refining the best one.
+	pretrainGridSize = 200
+
+	// pretrainSameDayReviews is the largest number of same-day reviews before a first long-term
+	// review that Pretrain accounts for; cards with more are left out.
+	pretrainSameDayReviews = 16
+)
+
+// recallKey groups the first long-term reviews made elapsedDays after the last of the same-day
+// ratings following a first rating.
+type recallKey struct {
+	elapsedDays int
+
+	// sameDay holds the same-day ratings in order, followed by zeros.
+	sameDay [pretrainSameDayReviews]fsrs.Rating
+}
+
+// recallCount counts the recalls among the first long-term reviews sharing a recallKey.
+type recallCount struct {
+	recallKey
+	recalled float64
+	total    float64
+}
+
+// Pretrain estimates the initial stabilities, the first four FSRS-6 weights, from the first
+// long-term review of every card in histories, grouped by the first rating of the card.
+//
+// Same-day reviews between the first rating and that review are accounted for with the
+// short-term stability of the initial parameters. For every first rating the stability that
+// best predicts whether those reviews were recalled is fitted, smoothed towards the initial
+// parameters for ratings with few reviews, and filled from the nearest rating for ratings
+// without reviews. The stabilities are then made non-decreasing from Again to Easy and clamped
+// to fsrs.StabilityMin and fsrs.InitialStabilityMax.
+func Pretrain(histories [][]fsrs.ReviewLog, options ...Option) ([4]float64, error) {
+	return PretrainSource(SliceSource(histories), options...)
+}
+
+// PretrainSource is like Pretrain but reads the review histories from src.
+func PretrainSource(src HistorySource, options ...Option) ([4]float64, error) {
+	o, err := newOptimizer(options)
+	if err != nil {
+		return [4]float64{}, err
+	}
+
+	return o.initialStabilities(context.Background(), src, 0)
+}
+
+// initialStabilities estimates the initial stabilities from src, which has total predicted
+// reviews or 0 when unknown.
+func (o *optimizer) initialStabilities(ctx context.Context, src HistorySource, total int) ([4]float64, error) {
+	progress := o.newProgress(PhasePretrain, total)
+
+	var counts [4]map[recallKey]*recallCount
+	for i := range counts {
+		counts[i] = make(map[recallKey]*recallCount)
+	}
+
+	err := forEach(ctx, src, func(history []fsrs.ReviewLog) error {
+		progress.add(countPredicted(history))
+
+		firstLongTermReviews(history, func(first fsrs.Rating, sameDay []fsrs.Rating, elapsedDays int, recalled bool) {
+			if len(sameDay) > pretrainSameDayReviews {
+				return
+			}
+			key := recallKey{elapsedDays: elapsedDays}
+			copy(key.sameDay[:], sameDay)
+
+			c, ok := counts[first-1][key]
+			if !ok {
+				c = &recallCount{recallKey: key}
+				counts[first-1][key] = c
+			}
+			c.total++
+			if recalled {
+				c.recalled++
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		return [4]float64{}, err
+	}
+	progress.end()
+
+	var (
+		m           = newModel(o.initialParameters)
+		stabilities [4]float64
+		weights     [4]float64
+		fitted      [4]bool
+		found       bool
+	)
+
+	for i := range counts {
+		points := make([]recallCount, 0, len(counts[i]))
+		for _, c := range counts[i] {
+			points = append(points, *c)
+			weights[i] += c.total
+		}
+		if weights[i] == 0 {
+			continue
+		}
+		// sum the losses in a fixed order so that the fit is reproducible
+		sort.Slice(points, func(a, b int) bool {
+			if points[a].elapsedDays != points[b].elapsedDays {
+				return points[a].elapsedDays < points[b].elapsedDays
+			}
+			return slices.Compare(points[a].sameDay[:], points[b].sameDay[:]) < 0
+		})
+
+		stabilities[i] = m.fitStability(points, weights[i], o.initialParameters[i])
+		fitted[i], found = true, true
+	}
+
+	if !found {
+		return [4]float64{}, ErrNotEnoughData
+	}
+
+	// fill the ratings without reviews from the nearest rating with reviews, keeping the
+	// ratio of their initial stabilities
+	for i := range stabilities {
+		if fitted[i] {
+			continue
+		}
+
+		nearest := -1
+		for j := range stabilities {
+			if fitted[j] && (nearest < 0 || abs(j-i) < abs(nearest-i)) {
+				nearest = j
+			}
+		}
+		stabilities[i] = stabilities[nearest] * o.initialParameters[i] / o.initialParameters[nearest]
+	}
+
+	for i := range weights {
+		weights[i] += pretrainPriorReviews
+	}
+	stabilities = nonDecreasing(stabilities, weights)
+
+	for i := range stabilities {
+		stabilities[i] = min(max(stabilities[i], fsrs.StabilityMin), fsrs.InitialStabilityMax)
+	}
+
+	return stabilities, nil
+}
+
+// firstLongTermReviews calls visit with the first rating of every learning of history, the
+// ratings of the same-day reviews following it, and the days to and outcome of the first
+// review made at least a day after the previous one.
+func firstLongTermReviews(history []fsrs.ReviewLog, visit func(first fsrs.Rating, sameDay []fsrs.Rating, elapsedDays int, recalled bool)) {
+	if len(history) == 0 {
+		return
+	}
+
+	var (
+		first      fsrs.Rating
+		sameDay    []fsrs.Rating
+		done       bool
+		lastReview = history[0].ReviewDatetime
+	)
+
+	for _, review := range history {
+		switch review.Kind {
+		case fsrs.ReviewReset:
+			first, sameDay, done = 0, nil, false
+			continue
+		case fsrs.ReviewRescheduled:
+			continue
+		}
+
+		elapsedDays := review.ReviewDatetime.Sub(lastReview).Hours() / 24
+		lastReview = review.ReviewDatetime
+
+		switch {
+		case first == 0:
+			first = review.Rating
+		case done:
+		case elapsedDays < 1:
+			sameDay = append(sameDay, review.Rating)
+		default:
+			visit(first, sameDay, int(math.Round(elapsedDays)), review.Rating > fsrs.Again)
+			done = true
+		}
+	}
+}
+
+// fitStability returns the initial stability minimizing the log loss of counts, total reviews
+// in all, together with pretrainPriorReviews pseudo reviews recalled as predicted by prior.
+func (m *model) fitStability(counts []recallCount, total, prior float64) float64 {
+	retrievability := func(c *recallCount, stability float64) float64 {
+		s := constant(stability)
+		for _, rating := range c.sameDay {
+			if rating == 0 {
+				break
+			}
+			s = m.shortTermStability(s, rating)
+		}
+
+		r := m.retrievability(float64(c.elapsedDays), s).v
+		return min(max(r, lossEpsilon), 1-lossEpsilon)
+	}
+
+	loss := func(logStability float64) float64 {
+		stability := math.Exp(logStability)
+
+		var l float64
+		for i := range counts {
+			c := &counts[i]
+			r := retrievability(c, stability)
+			pseudo := pretrainPriorReviews * c.total / total
+			recalled := c.recalled + pseudo*retrievability(c, prior)
+			forgotten := c.total + pseudo - recalled
+			l -= recalled*math.Log(r) + forgotten*math.Log(1-r)
+		}
+		return l
+	}
+
+	lo, hi := math.Log(fsrs.StabilityMin), math.Log(fsrs.InitialStabilityMax)
+	step := (hi - lo) / (pretrainGridSize - 1)
+
+	best, bestLoss := lo, math.Inf(1)
+	for i := 0; i < pretrainGridSize; i++ {
+		x := lo + float64(i)*step
+		if l := loss(x); l < bestLoss {
+			best, bestLoss = x, l
+		}
+	}
+
+	// golden-section search around the best grid point
+	a, b := max(best-step, lo), min(best+step, hi)
+	ratio := (math.Sqrt(5) - 1) / 2
+	for b-a > 1e-6 {
+		c, d := b-ratio*(b-a), a+ratio*(b-a)
+		if loss(c) < loss(d) {
+			b = d
+		} else {
+			a = c
+		}
+	}
+
+	return math.Exp((a + b) / 2)
+}
+
+// nonDecreasing returns the weighted least squares non-decreasing fit of the logarithms of
+// stabilities, using the pool adjacent violators algorithm.
+func nonDecreasing(stabilities, weights [4]float64) [4]float64 {
+	type block struct {
+		value  float64
+		weight float64
+		size   int
+	}
+
+	var blocks []block
+	for i, s := range stabilities {
+		blocks = append(blocks, block{value: math.Log(s), weight: weights[i], size: 1})
+		for len(blocks) > 1 && blocks[len(blocks)-2].value > blocks[len(blocks)-1].value {
+			a, b := blocks[len(blocks)-2], blocks[len(blocks)-1]
+			weight := a.weight + b.weight
+			blocks = append(blocks[:len(blocks)-2], block{
+				value:  (a.value*a.weight + b.value*b.weight) / weight,
+				weight: weight,
+				size:   a.size + b.size,
+			})
+		}
+	}
+
+	var (
+		result [4]float64
+		i      int
+	)
+	for _, b := range blocks {
+		for j := 0; j < b.size; j++ {
+			result[i] = math.Exp(b.value)
+			i++
+		}
+	}
+
+	return result
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,106 @@
+package optimizer
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/patricksuo/fsrs"
+	"github.com/stretchr/testify/assert"
+)
+
+// firstReviewHistories rates cards once and reviews them again one to twenty days later,
+// sampling recall from the initial stability of parameters.
+func firstReviewHistories(parameters []float64, cards int, seed int64) [][]fsrs.ReviewLog {
+	var (
+		r      = rand.New(rand.NewSource(seed))
+		decay  = -parameters[20]
+		factor = math.Pow(0.9, 1/decay) - 1
+		start  = time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	)
+
+	histories := make([][]fsrs.ReviewLog, cards)
+	for i := range histories {
+		var (
+			id          = int64(i + 1)
+			first       = fsrs.Rating(1 + r.Intn(4))
+			elapsedDays = 1 + r.Intn(20)
+			rating      = fsrs.Again
+		)
+		if r.Float64() < math.Pow(1+factor*float64(elapsedDays)/parameters[first-1], decay) {
+			rating = fsrs.Good
+		}
+
+		histories[i] = []fsrs.ReviewLog{
+			{CardID: id, Rating: first, ReviewDatetime: start},
+			{CardID: id, Rating: rating, ReviewDatetime: start.Add(time.Duration(elapsedDays) * 24 * time.Hour)},
+		}
+	}
+
+	return histories
+}
+
+func TestPretrain(t *testing.T) {
+	histories := firstReviewHistories(trueParameters, 4000, 5)
+
+	stabilities, err := Pretrain(histories)
+	assert.NoError(t, err)
+
+	var pretrained, initial float64
+	for i, s := range stabilities {
+		assert.GreaterOrEqual(t, s, fsrs.StabilityMin)
+		assert.LessOrEqual(t, s, fsrs.InitialStabilityMax)
+		if i > 0 {
+			assert.GreaterOrEqual(t, s, stabilities[i-1])
+		}
+
+		pretrained += math.Abs(math.Log(s / trueParameters[i]))
+		initial += math.Abs(math.Log(fsrs.DefaultParameters[i] / trueParameters[i]))
+	}
+	assert.Less(t, pretrained, initial)
+}
+
+func TestPretrainSmoothing(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	// every card rated Good first and recalled three days later
+	var histories [][]fsrs.ReviewLog
+	for id := int64(1); id <= 3; id++ {
+		histories = append(histories, []fsrs.ReviewLog{
+			{CardID: id, Rating: fsrs.Good, ReviewDatetime: now},
+			{CardID: id, Rating: fsrs.Good, ReviewDatetime: now.Add(10 * time.Minute)},
+			{CardID: id, Rating: fsrs.Good, ReviewDatetime: now.Add(3 * 24 * time.Hour)},
+		})
+	}
+
+	stabilities, err := Pretrain(histories)
+	assert.NoError(t, err)
+
+	// a few recalls raise the Good stability without reaching the maximum
+	assert.Greater(t, stabilities[2], fsrs.DefaultParameters[2])
+	assert.Less(t, stabilities[2], fsrs.InitialStabilityMax)
+
+	// ratings without reviews keep the ratio of the initial stabilities
+	assert.InEpsilon(t, stabilities[2]*fsrs.DefaultParameters[3]/fsrs.DefaultParameters[2], stabilities[3], 1e-9)
+	assert.InEpsilon(t, stabilities[2]*fsrs.DefaultParameters[0]/fsrs.DefaultParameters[2], stabilities[0], 1e-9)
+
+	// same-day reviews only are not enough
+	_, err = Pretrain([][]fsrs.ReviewLog{histories[0][:2]})
+	assert.ErrorIs(t, err, ErrNotEnoughData)
+}
+
+func TestOptimizeFreezeInitialStability(t *testing.T) {
+	histories := syntheticHistories(trueParameters, 100, 8, 1)
+
+	stabilities, err := Pretrain(histories)
+	assert.NoError(t, err)
+
+	params, err := Optimize(histories, WithFreezeInitialStability(true))
+	assert.NoError(t, err)
+	assert.Equal(t, stabilities[:], params[:4])
+
+	params, err = Optimize(histories, WithFreezeInitialStability(true), WithPretrain(false))
+	assert.NoError(t, err)
+	assert.Equal(t, fsrs.DefaultParameters[:4], params[:4])
+}
@@ -0,0 +1,275 @@
+package optimizer
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/patricksuo/fsrs"
+)
+
+// HistorySource yields review histories card by card, so that datasets larger than memory can
+// be optimized and evaluated.
+type HistorySource interface {
+	// Next returns the time-ordered review history of the next card, or io.EOF after the last card.
+	Next() ([]fsrs.ReviewLog, error)
+
+	// Reset rewinds the source to its first card, ErrNotResettable when the source cannot be read again.
+	Reset() error
+}
+
+// sliceSource is the HistorySource of histories held in memory.
+type sliceSource struct {
+	histories [][]fsrs.ReviewLog
+	next      int
+}
+
+// SliceSource returns a HistorySource yielding histories, one time-ordered slice of ReviewLogs per card.
+func SliceSource(histories [][]fsrs.ReviewLog) HistorySource {
+	return &sliceSource{histories: histories}
+}
+
+func (s *sliceSource) Next() ([]fsrs.ReviewLog, error) {
+	if s.next >= len(s.histories) {
+		return nil, io.EOF
+	}
+
+	history := s.histories[s.next]
+	s.next++
+	return history, nil
+}
+
+func (s *sliceSource) Reset() error {
+	s.next = 0
+	return nil
+}
+
+// logReader reads ReviewLogs one by one.
+type logReader interface {
+	read() (fsrs.ReviewLog, error)
+}
+
+// groupingSource groups the consecutive ReviewLogs of a card read from a stream into histories,
+// the cards in ascending ID order.
+type groupingSource struct {
+	r       io.Reader
+	newLogs func(io.Reader) (logReader, error)
+	logs    logReader
+
+	// read is the number of logs read since the last reset.
+	read int
+
+	// pending is the first log of the next history.
+	pending *fsrs.ReviewLog
+
+	// last is the ID of the card whose history was returned last since the last reset, nil before.
+	last *int64
+}
+
+func newGroupingSource(r io.Reader, newLogs func(io.Reader) (logReader, error)) *groupingSource {
+	return &groupingSource{r: r, newLogs: newLogs}
+}
+
+func (s *groupingSource) Next() ([]fsrs.ReviewLog, error) {
+	if s.logs == nil {
+		logs, err := s.newLogs(s.r)
+		if err != nil {
+			return nil, err
+		}
+		s.logs = logs
+	}
+
+	var history []fsrs.ReviewLog
+	if s.pending != nil {
+		history = append(history, *s.pending)
+		s.pending = nil
+	}
+
+	for {
+		log, err := s.logs.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w log %d: %w", ErrInvalidHistory, s.read, err)
+		}
+		s.read++
+
+		if len(history) > 0 && log.CardID != history[0].CardID {
+			s.pending = &log
+			break
+		}
+		history = append(history, log)
+	}
+
+	if len(history) == 0 {
+		return nil, io.EOF
+	}
+
+	id := history[0].CardID
+	if s.last != nil && id <= *s.last {
+		return nil, fmt.Errorf("%w card %d follows card %d, the logs must be grouped by card in ascending ID order", ErrInvalidHistory, id, *s.last)
+	}
+	s.last = &id
+
+	return history, nil
+}
+
+func (s *groupingSource) Reset() error {
+	if s.logs == nil {
+		return nil
+	}
+
+	seeker, ok := s.r.(io.Seeker)
+	if !ok {
+		return ErrNotResettable
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	s.logs, s.read, s.pending, s.last = nil, 0, nil, nil
+	return nil
+}
+
+// NewJSONLSource returns a HistorySource reading r as a stream of JSON encoded fsrs.ReviewLogs,
+// usually one per line.
+//
+// The logs must be grouped by card in ascending card ID order, the logs of a card ordered by
+// ReviewDatetime. Only the logs of one card are held in memory at a time. The source can be reset when r implements io.Seeker.
+func NewJSONLSource(r io.Reader) HistorySource {
+	return newGroupingSource(r, func(r io.Reader) (logReader, error) {
+		return jsonLogReader{json.NewDecoder(r)}, nil
+	})
+}
+
+type jsonLogReader struct {
+	decoder *json.Decoder
+}
+
+func (r jsonLogReader) read() (fsrs.ReviewLog, error) {
+	var log fsrs.ReviewLog
+	err := r.decoder.Decode(&log)
+	return log, err
+}
+
+// NewCSVSource returns a HistorySource reading r as CSV with a header row naming the columns
+// after the JSON fields of fsrs.ReviewLog.
+//
+// The card_id, rating and review_datetime columns are required, review_datetime is formatted as
+// RFC 3339 and rating, state and kind are numbers. The logs must be grouped by card in ascending
+// card_id order, the logs of a card ordered by review_datetime. The source can be reset when r implements io.Seeker.
+func NewCSVSource(r io.Reader) HistorySource {
+	return newGroupingSource(r, newCSVLogReader)
+}
+
+type csvLogReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVLogReader(r io.Reader) (logReader, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w csv header: %w", ErrInvalidHistory, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range []string{"card_id", "rating", "review_datetime"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w csv header has no %s column", ErrInvalidHistory, name)
+		}
+	}
+
+	return &csvLogReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvLogReader) read() (fsrs.ReviewLog, error) {
+	var log fsrs.ReviewLog
+
+	record, err := r.reader.Read()
+	if err != nil {
+		return log, err
+	}
+
+	// field parses the column name of record with parse, leaving missing columns unset
+	field := func(name string, parse func(string) error) {
+		i, ok := r.columns[name]
+		if !ok || err != nil {
+			return
+		}
+		if perr := parse(record[i]); perr != nil {
+			err = fmt.Errorf("column %s: %w", name, perr)
+		}
+	}
+	parseInt := func(set func(int)) func(string) error {
+		return func(value string) error {
+			n, err := strconv.Atoi(value)
+			set(n)
+			return err
+		}
+	}
+	parseFloat := func(set func(float64)) func(string) error {
+		return func(value string) error {
+			f, err := strconv.ParseFloat(value, 64)
+			set(f)
+			return err
+		}
+	}
+
+	field("card_id", func(value string) (err error) {
+		log.CardID, err = strconv.ParseInt(value, 10, 64)
+		return err
+	})
+	field("rating", parseInt(func(n int) { log.Rating = fsrs.Rating(n) }))
+	field("review_datetime", func(value string) (err error) {
+		log.ReviewDatetime, err = time.Parse(time.RFC3339Nano, value)
+		return err
+	})
+	field("state", parseInt(func(n int) { log.State = fsrs.State(n) }))
+	field("elapsed_days", parseFloat(func(f float64) { log.ElapsedDays = f }))
+	field("scheduled_days", parseFloat(func(f float64) { log.ScheduledDays = f }))
+	field("kind", parseInt(func(n int) { log.Kind = fsrs.ReviewKind(n) }))
+
+	return log, err
+}
+
+// forEach calls visit with every history of src from its first card, after validating it, and
+// stops with the error of ctx once it is done.
+func forEach(ctx context.Context, src HistorySource, visit func(history []fsrs.ReviewLog) error) error {
+	if err := src.Reset(); err != nil {
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		history, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := validateHistory(history); err != nil {
+			return err
+		}
+		if err := visit(history); err != nil {
+			return err
+		}
+	}
+}
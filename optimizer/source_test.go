@@ -0,0 +1,116 @@
+package optimizer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patricksuo/fsrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeJSONL(histories [][]fsrs.ReviewLog) []byte {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, history := range histories {
+		for _, review := range history {
+			if err := encoder.Encode(review); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+func encodeCSV(histories [][]fsrs.ReviewLog) []byte {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write([]string{"review_datetime", "card_id", "rating", "state", "kind"})
+	for _, history := range histories {
+		for _, review := range history {
+			_ = writer.Write([]string{
+				review.ReviewDatetime.Format(time.RFC3339Nano),
+				strconv.FormatInt(review.CardID, 10),
+				strconv.Itoa(int(review.Rating)),
+				strconv.Itoa(int(review.State)),
+				strconv.Itoa(int(review.Kind)),
+			})
+		}
+	}
+	writer.Flush()
+	return buf.Bytes()
+}
+
+// onlyReader hides the io.Seeker of a reader.
+type onlyReader struct {
+	io.Reader
+}
+
+func TestHistorySources(t *testing.T) {
+	histories := syntheticHistories(trueParameters, 100, 8, 1)
+
+	expected, err := EvaluateSource(SliceSource(histories), trueParameters)
+	assert.NoError(t, err)
+	expectedParams, err := Optimize(histories, WithEpochs(2))
+	assert.NoError(t, err)
+
+	for name, newSource := range map[string]func() HistorySource{
+		"slice": func() HistorySource { return SliceSource(histories) },
+		"jsonl": func() HistorySource { return NewJSONLSource(bytes.NewReader(encodeJSONL(histories))) },
+		"csv":   func() HistorySource { return NewCSVSource(bytes.NewReader(encodeCSV(histories))) },
+	} {
+		metrics, err := EvaluateSource(newSource(), trueParameters)
+		assert.NoError(t, err, name)
+		assert.Equal(t, expected, metrics, name)
+
+		params, err := OptimizeSource(newSource(), WithEpochs(2))
+		assert.NoError(t, err, name)
+		assert.Equal(t, expectedParams, params, name)
+
+		_, err = CalibrateSource(newSource(), trueParameters, 10)
+		assert.NoError(t, err, name)
+	}
+
+	// a single pass does not need a seekable reader
+	metrics, err := EvaluateSource(NewJSONLSource(onlyReader{bytes.NewReader(encodeJSONL(histories))}), trueParameters)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, metrics)
+
+	_, err = OptimizeSource(NewJSONLSource(onlyReader{bytes.NewReader(encodeJSONL(histories))}))
+	assert.ErrorIs(t, err, ErrNotResettable)
+}
+
+func TestInvalidHistorySources(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	log := func(id int64, days int) fsrs.ReviewLog {
+		return fsrs.ReviewLog{CardID: id, Rating: fsrs.Good, ReviewDatetime: now.AddDate(0, 0, days)}
+	}
+
+	// the logs of card 1 are split by the logs of card 2
+	split := [][]fsrs.ReviewLog{{log(1, 0), log(1, 3)}, {log(2, 0), log(2, 3)}, {log(1, 7)}}
+	_, err := EvaluateSource(NewJSONLSource(bytes.NewReader(encodeJSONL(split))), trueParameters)
+	assert.ErrorIs(t, err, ErrInvalidHistory)
+
+	// the cards are not in ascending ID order
+	descending := [][]fsrs.ReviewLog{{log(2, 0), log(2, 3)}, {log(1, 0), log(1, 3)}}
+	_, err = EvaluateSource(NewCSVSource(bytes.NewReader(encodeCSV(descending))), trueParameters)
+	assert.ErrorIs(t, err, ErrInvalidHistory)
+
+	unordered := [][]fsrs.ReviewLog{{log(1, 3), log(1, 0)}}
+	_, err = EvaluateSource(NewCSVSource(bytes.NewReader(encodeCSV(unordered))), trueParameters)
+	assert.ErrorIs(t, err, ErrInvalidHistory)
+
+	_, err = EvaluateSource(NewJSONLSource(strings.NewReader(`{"card_id": 1, "rating": 3`)), trueParameters)
+	assert.ErrorIs(t, err, ErrInvalidHistory)
+
+	_, err = EvaluateSource(NewCSVSource(strings.NewReader("card_id,rating\n1,3\n")), trueParameters)
+	assert.ErrorIs(t, err, ErrInvalidHistory)
+
+	_, err = EvaluateSource(NewCSVSource(strings.NewReader("card_id,rating,review_datetime\n1,good,2024-01-01T09:00:00Z\n")), trueParameters)
+	assert.ErrorIs(t, err, ErrInvalidHistory)
+}
@@ -0,0 +1,38 @@
+package optimizer
+
+import (
+	"sync"
+
+	"github.com/patricksuo/fsrs"
+)
+
+// batchLoss returns the summed loss of the histories of batch under m together with its
+// gradient, computed by o.workers goroutines and summed in the order of batch.
+func (o *optimizer) batchLoss(m *model, batch [][]fsrs.ReviewLog) dual {
+	losses := make([]dual, len(batch))
+
+	workers := min(o.workers, len(batch))
+	if workers <= 1 {
+		for i, history := range batch {
+			losses[i], _ = m.cardLoss(history)
+		}
+	} else {
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := w; i < len(batch); i += workers {
+					losses[i], _ = m.cardLoss(batch[i])
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	var loss dual
+	for _, l := range losses {
+		loss = loss.add(l)
+	}
+	return loss
+}
@@ -0,0 +1,101 @@
+package optimizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimizeWorkersDeterministic(t *testing.T) {
+	histories := syntheticHistories(trueParameters, 200, 8, 1)
+
+	serial, err := Optimize(histories, WithEpochs(2), WithBatchSize(128), WithWorkers(1))
+	assert.NoError(t, err)
+
+	for _, workers := range []int{2, 3, 8} {
+		parallel, err := Optimize(histories, WithEpochs(2), WithBatchSize(128), WithWorkers(workers))
+		assert.NoError(t, err)
+		assert.Equal(t, serial, parallel, "workers %d", workers)
+	}
+
+	_, err = Optimize(histories, WithWorkers(0))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestOptimizeProgress(t *testing.T) {
+	histories := syntheticHistories(trueParameters, 100, 6, 1)
+
+	var total int
+	for _, history := range histories {
+		total += countPredicted(history)
+	}
+
+	phases := make(map[Phase][]Progress)
+	var last Phase
+	_, err := Optimize(histories, WithEpochs(2), WithBatchSize(64), WithProgress(func(p Progress) {
+		assert.GreaterOrEqual(t, p.Phase, last)
+		last = p.Phase
+		phases[p.Phase] = append(phases[p.Phase], p)
+	}))
+	assert.NoError(t, err)
+
+	for _, phase := range []Phase{PhaseCount, PhasePretrain} {
+		progress := phases[phase]
+		assert.Greater(t, len(progress), 1, "phase %d", phase)
+		assert.Equal(t, total, progress[len(progress)-1].Reviews, "phase %d", phase)
+		for _, p := range progress {
+			assert.Equal(t, 0, p.Epoch)
+			assert.Equal(t, 0.0, p.Loss)
+		}
+	}
+	assert.Equal(t, 0, phases[PhaseCount][0].TotalReviews)
+	assert.Equal(t, total, phases[PhasePretrain][0].TotalReviews)
+
+	progress := phases[PhaseOptimize]
+	assert.NotEmpty(t, progress)
+	for i, p := range progress {
+		assert.Equal(t, 2, p.Epochs)
+		assert.Equal(t, total, p.TotalReviews)
+		assert.Greater(t, p.Loss, 0.0)
+		assert.LessOrEqual(t, p.Reviews, total)
+
+		if i > 0 && p.Epoch == progress[i-1].Epoch {
+			assert.Greater(t, p.Reviews, progress[i-1].Reviews)
+		}
+	}
+
+	end := progress[len(progress)-1]
+	assert.Equal(t, 2, end.Epoch)
+	assert.Equal(t, total, end.Reviews)
+}
+
+func TestOptimizeContextCancel(t *testing.T) {
+	histories := syntheticHistories(trueParameters, 100, 6, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := OptimizeContext(ctx, SliceSource(histories))
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// every pass stops at the next card once cancelled
+	for _, phase := range []Phase{PhaseCount, PhasePretrain, PhaseOptimize} {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var reports []Progress
+		_, err = OptimizeContext(ctx, SliceSource(histories), WithBatchSize(64), WithProgress(func(p Progress) {
+			reports = append(reports, p)
+			if p.Phase == phase {
+				cancel()
+			}
+		}))
+		cancel()
+
+		assert.ErrorIs(t, err, context.Canceled, "phase %d", phase)
+		assert.Equal(t, phase, reports[len(reports)-1].Phase)
+		for _, p := range reports[:len(reports)-1] {
+			assert.Less(t, p.Phase, phase)
+		}
+	}
+}
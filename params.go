@@ -1,9 +1,12 @@
 package fsrs
 
+import "fmt"
+
 // Constants for FSRS parameters
 const (
 	StabilityMin        = 0.001
 	InitialStabilityMax = 100.0
+	StabilityMax        = 36500.0
 
 	MinDifficulty = 1.0
 	MaxDifficulty = 10.0
@@ -83,3 +86,220 @@ var UpperBoundsParameters = []float64{
 	0.8,
 	0.8,
 }
+
+// lowerBoundsFSRS45 and upperBoundsFSRS45 are the bounds of FSRS-4.5 weights, which allow
+// wider difficulty weights than LowerBoundsParameters and UpperBoundsParameters.
+var (
+	lowerBoundsFSRS45 = []float64{
+		StabilityMin, StabilityMin, StabilityMin, StabilityMin,
+		1.0, 0.1, 0.1, 0.0, 0.0, 0.0, 0.01, 0.1, 0.01, 0.01, 0.01, 0.0, 1.0,
+	}
+	upperBoundsFSRS45 = []float64{
+		InitialStabilityMax, InitialStabilityMax, InitialStabilityMax, InitialStabilityMax,
+		10.0, 5.0, 5.0, 0.75, 4.5, 0.8, 3.5, 5.0, 0.25, 0.9, 4.0, 1.0, 6.0,
+	}
+)
+
+// AlgorithmVersion identifies the version of the FSRS algorithm a set of weights belongs to.
+type AlgorithmVersion int
+
+const (
+	// FSRS6 is FSRS-6, with 21 weights.
+	FSRS6 AlgorithmVersion = iota
+
+	// FSRS5 is FSRS-5, with 19 weights. Its decay is fixed to 0.5 and the stability change of
+	// same-day reviews does not depend on the stability.
+	FSRS5
+
+	// FSRS45 is FSRS-4.5, with 17 weights. Its decay is fixed to 0.5, the initial difficulty is
+	// linear in the rating, difficulty reverts to the initial Good difficulty without damping,
+	// and same-day reviews have no short-term stability.
+	FSRS45
+)
+
+// numWeights returns the length of the weight vector of v.
+func (v AlgorithmVersion) numWeights() int {
+	switch v {
+	case FSRS5:
+		return 19
+	case FSRS45:
+		return 17
+	default:
+		return 21
+	}
+}
+
+// bounds returns the lower and upper bounds of the weights of v. FSRS-5 shares the bounds of
+// the first 19 FSRS-6 weights.
+func (v AlgorithmVersion) bounds() ([]float64, []float64) {
+	if v == FSRS45 {
+		return lowerBoundsFSRS45, upperBoundsFSRS45
+	}
+
+	n := v.numWeights()
+	return LowerBoundsParameters[:n], UpperBoundsParameters[:n]
+}
+
+func (v AlgorithmVersion) String() string {
+	switch v {
+	case FSRS6:
+		return "FSRS-6"
+	case FSRS5:
+		return "FSRS-5"
+	case FSRS45:
+		return "FSRS-4.5"
+	default:
+		return fmt.Sprintf("AlgorithmVersion(%d)", int(v))
+	}
+}
+
+// Parameters are the FSRS model weights with named fields, in the order of the weight vector.
+//
+// Weights that do not exist in Version are ignored, Decay is 0.5 before FSRS-6.
+type Parameters struct {
+	// Version is the version of the algorithm the weights belong to.
+	Version AlgorithmVersion
+
+	// InitialStability is the stability after the first review, by rating (w0 to w3).
+	InitialStability [4]float64
+
+	// InitialDifficulty is the difficulty after a first review rated Again (w4).
+	InitialDifficulty float64
+
+	// InitialDifficultyScale scales the difficulty decrease of higher first ratings (w5).
+	InitialDifficultyScale float64
+
+	// DifficultyDelta is the difficulty change per rating step away from Good (w6).
+	DifficultyDelta float64
+
+	// DifficultyMeanReversion is the weight of the initial Easy difficulty in every difficulty update (w7).
+	DifficultyMeanReversion float64
+
+	// RecallStabilityScale is the logarithm of the stability increase factor of a recalled card (w8).
+	RecallStabilityScale float64
+
+	// RecallStabilityDecay is the exponent damping the stability increase of stable cards (w9).
+	RecallStabilityDecay float64
+
+	// RecallRetrievabilityFactor increases the stability gain of cards recalled at low retrievability (w10).
+	RecallRetrievabilityFactor float64
+
+	// ForgetStabilityScale scales the stability of a forgotten card (w11).
+	ForgetStabilityScale float64
+
+	// ForgetDifficultyDecay is the exponent of the difficulty in the stability of a forgotten card (w12).
+	ForgetDifficultyDecay float64
+
+	// ForgetStabilityExponent is the exponent of the previous stability in the stability of a forgotten card (w13).
+	ForgetStabilityExponent float64
+
+	// ForgetRetrievabilityFactor increases the stability of cards forgotten at low retrievability (w14).
+	ForgetRetrievabilityFactor float64
+
+	// HardPenalty multiplies the stability gain of a review rated Hard (w15).
+	HardPenalty float64
+
+	// EasyBonus multiplies the stability gain of a review rated Easy (w16).
+	EasyBonus float64
+
+	// ShortTermRatingScale scales the stability change of same-day reviews per rating step (w17).
+	ShortTermRatingScale float64
+
+	// ShortTermRatingOffset shifts the rating of same-day reviews (w18).
+	ShortTermRatingOffset float64
+
+	// ShortTermStabilityDecay damps the stability change of same-day reviews of stable cards (w19).
+	ShortTermStabilityDecay float64
+
+	// Decay is the negated exponent of the forgetting curve (w20).
+	Decay float64
+}
+
+// ParametersFromVector returns the Parameters of the weight vector, which must have 21 (FSRS-6),
+// 19 (FSRS-5) or 17 (FSRS-4.5) weights within the bounds of their version: the matching
+// LowerBoundsParameters and UpperBoundsParameters, or the wider FSRS-4.5 bounds of fsrs-rs for
+// 17 weights. vector is copied.
+func ParametersFromVector(vector []float64) (Parameters, error) {
+	if err := validateParameters(vector); err != nil {
+		return Parameters{}, err
+	}
+
+	return parametersFromVector(vector), nil
+}
+
+func parametersFromVector(w []float64) Parameters {
+	p := Parameters{
+		Version:                    FSRS6,
+		InitialStability:           [4]float64{w[0], w[1], w[2], w[3]},
+		InitialDifficulty:          w[4],
+		InitialDifficultyScale:     w[5],
+		DifficultyDelta:            w[6],
+		DifficultyMeanReversion:    w[7],
+		RecallStabilityScale:       w[8],
+		RecallStabilityDecay:       w[9],
+		RecallRetrievabilityFactor: w[10],
+		ForgetStabilityScale:       w[11],
+		ForgetDifficultyDecay:      w[12],
+		ForgetStabilityExponent:    w[13],
+		ForgetRetrievabilityFactor: w[14],
+		HardPenalty:                w[15],
+		EasyBonus:                  w[16],
+		Decay:                      0.5,
+	}
+
+	switch len(w) {
+	case FSRS45.numWeights():
+		p.Version = FSRS45
+	case FSRS5.numWeights():
+		p.Version = FSRS5
+		p.ShortTermRatingScale, p.ShortTermRatingOffset = w[17], w[18]
+	default:
+		p.ShortTermRatingScale, p.ShortTermRatingOffset = w[17], w[18]
+		p.ShortTermStabilityDecay, p.Decay = w[19], w[20]
+	}
+
+	return p
+}
+
+// normalized returns p with the weights that do not exist in its version reset.
+func (p Parameters) normalized() Parameters {
+	return parametersFromVector(p.Vector())
+}
+
+// Vector returns a new weight vector of p, as long as the weight vector of its version.
+func (p Parameters) Vector() []float64 {
+	vector := []float64{
+		p.InitialStability[0],
+		p.InitialStability[1],
+		p.InitialStability[2],
+		p.InitialStability[3],
+		p.InitialDifficulty,
+		p.InitialDifficultyScale,
+		p.DifficultyDelta,
+		p.DifficultyMeanReversion,
+		p.RecallStabilityScale,
+		p.RecallStabilityDecay,
+		p.RecallRetrievabilityFactor,
+		p.ForgetStabilityScale,
+		p.ForgetDifficultyDecay,
+		p.ForgetStabilityExponent,
+		p.ForgetRetrievabilityFactor,
+		p.HardPenalty,
+		p.EasyBonus,
+		p.ShortTermRatingScale,
+		p.ShortTermRatingOffset,
+		p.ShortTermStabilityDecay,
+		p.Decay,
+	}
+
+	return vector[:p.Version.numWeights()]
+}
+
+// Validate checks that every weight of p is within the bounds of its version.
+func (p Parameters) Validate() error {
+	if p.Version < FSRS6 || p.Version > FSRS45 {
+		return fmt.Errorf("%w unknown algorithm version %v", ErrInvalidParam, p.Version)
+	}
+
+	return validateParameters(p.Vector())
+}
@@ -0,0 +1,142 @@
+package fsrs
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParametersVector(t *testing.T) {
+	parameters, err := ParametersFromVector(DefaultParameters)
+	assert.NoError(t, err)
+	assert.Equal(t, FSRS6, parameters.Version)
+	assert.Equal(t, [4]float64{0.2172, 1.1771, 3.2602, 16.1507}, parameters.InitialStability)
+	assert.Equal(t, 0.3332, parameters.ShortTermRatingOffset)
+	assert.Equal(t, 0.2, parameters.Decay)
+	assert.Equal(t, DefaultParameters, parameters.Vector())
+
+	// the vector is copied both ways
+	vector := parameters.Vector()
+	vector[20] = 0.5
+	assert.Equal(t, 0.2, parameters.Decay)
+	assert.Equal(t, 0.2, DefaultParameters[20])
+
+	_, err = ParametersFromVector(DefaultParameters[:20])
+	assert.ErrorIs(t, err, ErrInvalidParam)
+
+	parameters.Decay = 0.9
+	assert.ErrorIs(t, parameters.Validate(), ErrInvalidParam)
+}
+
+func TestSchedulerParametersCopied(t *testing.T) {
+	vector := append([]float64(nil), DefaultParameters...)
+	scheduler := mustNewScheduler(WithParameters(vector))
+
+	vector[0] = 1
+	assert.Equal(t, 0.2172, scheduler.Parameters().InitialStability[0])
+
+	parameters := scheduler.Parameters()
+	parameters.InitialStability[0] = 1
+	assert.Equal(t, 0.2172, scheduler.Parameters().InitialStability[0])
+
+	snapshot := scheduler.Snapshot()
+	snapshot.Parameters[0] = 1
+	assert.Equal(t, 0.2172, scheduler.Parameters().InitialStability[0])
+	assert.Equal(t, 0.2172, DefaultParameters[0])
+
+	parameters.Decay = 0.5
+	named := mustNewScheduler(WithNamedParameters(parameters))
+	assert.Equal(t, parameters, named.Parameters())
+	assert.Equal(t, -0.5, named.decay)
+
+	_, err := NewScheduler(WithNamedParameters(Parameters{}))
+	assert.ErrorIs(t, err, ErrInvalidParam)
+}
+
+var (
+	fsrs5Parameters = []float64{
+		0.40255, 1.18385, 3.173, 15.69105, 7.1949, 0.5345, 1.4604, 0.0046, 1.54575, 0.1192,
+		1.01925, 1.9395, 0.11, 0.29605, 2.2698, 0.2315, 2.9898, 0.51655, 0.6621,
+	}
+	fsrs45Parameters = []float64{
+		0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031, 1.6474, 0.1367,
+		1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+	}
+)
+
+func TestVersionBounds(t *testing.T) {
+	// FSRS-4.5 allows difficulty weights FSRS-6 rejects
+	vector := append([]float64(nil), fsrs45Parameters...)
+	vector[5], vector[6] = 4.5, 4.8
+	parameters, err := ParametersFromVector(vector)
+	assert.NoError(t, err)
+	assert.Equal(t, FSRS45, parameters.Version)
+
+	vector = append([]float64(nil), DefaultParameters...)
+	vector[5] = 4.5
+	_, err = ParametersFromVector(vector)
+	assert.ErrorIs(t, err, ErrInvalidParam)
+
+	vector = append([]float64(nil), fsrs45Parameters...)
+	vector[6] = 5.5
+	_, err = ParametersFromVector(vector)
+	assert.ErrorIs(t, err, ErrInvalidParam)
+}
+
+func TestAlgorithmVersions(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, FSRS6, mustNewScheduler().Version())
+
+	for _, tc := range []struct {
+		vector  []float64
+		version AlgorithmVersion
+	}{
+		{fsrs5Parameters, FSRS5},
+		{fsrs45Parameters, FSRS45},
+	} {
+		scheduler := mustNewScheduler(WithParameters(tc.vector), WithEnableFuzzing(false))
+		assert.Equal(t, tc.version, scheduler.Version())
+		assert.Equal(t, tc.vector, scheduler.Parameters().Vector())
+		assert.Equal(t, -0.5, scheduler.decay)
+		assert.InDelta(t, 19.0/81, scheduler.factor, 1e-12)
+
+		restored, err := NewSchedulerFromSnapshot(scheduler.Snapshot())
+		assert.NoError(t, err)
+		assert.Equal(t, tc.version, restored.Version())
+
+		// at the desired retention of 0.9 the interval is the stability
+		card := scheduler.ReviewCard(NewEmptyCard(1), Easy, now)
+		assert.Equal(t, tc.vector[3], card.Stability)
+		assert.Equal(t, math.Round(tc.vector[3]), card.Due.Sub(now).Hours()/24)
+	}
+
+	// FSRS-5 same-day reviews ignore the stability
+	scheduler := mustNewScheduler(WithParameters(fsrs5Parameters), WithEnableFuzzing(false))
+	card := scheduler.ReviewCard(NewEmptyCard(1), Good, now)
+	card = scheduler.ReviewCard(card, Good, now.Add(10*time.Minute))
+	assert.InDelta(t, fsrs5Parameters[2]*math.Exp(fsrs5Parameters[17]*fsrs5Parameters[18]), card.Stability, 1e-9)
+
+	// FSRS-4.5 initial difficulty is linear, same-day reviews barely change the stability
+	scheduler = mustNewScheduler(WithParameters(fsrs45Parameters), WithEnableFuzzing(false))
+	assert.InDelta(t, 5.1618+2*1.2298, scheduler.initialDifficulty(Again), 1e-9)
+	assert.InDelta(t, 5.1618, scheduler.initialDifficulty(Good), 1e-9)
+	assert.InDelta(t, 5.1618, scheduler.nextDifficulty(5.1618, Good), 1e-9)
+	card = scheduler.ReviewCard(NewEmptyCard(1), Good, now)
+	card = scheduler.ReviewCard(card, Good, now.Add(10*time.Minute))
+	assert.InEpsilon(t, fsrs45Parameters[2], card.Stability, 0.01)
+
+	// weights missing from the version are ignored
+	parameters, err := ParametersFromVector(fsrs5Parameters)
+	assert.NoError(t, err)
+	parameters.Decay = 0.3
+	parameters.ShortTermStabilityDecay = 0.5
+	scheduler = mustNewScheduler(WithNamedParameters(parameters))
+	assert.Equal(t, -0.5, scheduler.decay)
+	assert.Equal(t, 0.0, scheduler.Parameters().ShortTermStabilityDecay)
+
+	_, err = NewScheduler(WithParameters(DefaultParameters[:18]))
+	assert.ErrorIs(t, err, ErrInvalidParam)
+}
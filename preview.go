@@ -0,0 +1,87 @@
+package fsrs
+
+import "time"
+
+// PreviewItem is the outcome of rating a card in a Preview.
+type PreviewItem struct {
+	// Card is the card after the review.
+	Card *Card
+
+	// Log records the review.
+	Log *ReviewLog
+
+	// Interval is the time until Card is due.
+	Interval time.Duration
+}
+
+// Preview holds the outcome of every rating of a card reviewed at the same time.
+type Preview struct {
+	Again PreviewItem
+	Hard  PreviewItem
+	Good  PreviewItem
+	Easy  PreviewItem
+}
+
+// Item returns the outcome of rating.
+func (p *Preview) Item(rating Rating) *PreviewItem {
+	switch rating {
+	case Again:
+		return &p.Again
+	case Hard:
+		return &p.Hard
+	case Good:
+		return &p.Good
+	case Easy:
+		return &p.Easy
+	}
+	return nil
+}
+
+// Preview reviews card at now with every rating.
+//
+// All ratings share a single fuzz draw, so the intervals are consistent with each other.
+// To commit the review the user picked, store the Card of its PreviewItem instead of calling
+// ReviewCard again, which would draw a new fuzz.
+//
+// It panics when ReviewCard does.
+func (s *Scheduler) Preview(card *Card, now time.Time) *Preview {
+	preview, err := s.preview(card, now)
+	if err != nil {
+		panic(err)
+	}
+
+	return preview
+}
+
+// TryPreview is like Preview but returns the errors of TryReviewCard instead of panicking.
+func (s *Scheduler) TryPreview(card *Card, now time.Time) (*Preview, error) {
+	if err := validateReviewDatetime(card, now); err != nil {
+		return nil, err
+	}
+
+	return s.preview(card, now)
+}
+
+func (s *Scheduler) preview(card *Card, now time.Time) (*Preview, error) {
+	var fuzz func() float64
+	if s.enableFuzzing {
+		fuzz = sharedFuzz(s.fuzzSource(card))
+	}
+
+	// the ratings share the due counts, the counter is called at most once per day
+	counter := s.dueCounts()
+
+	preview := &Preview{}
+	for _, rating := range []Rating{Again, Hard, Good, Easy} {
+		item := preview.Item(rating)
+
+		var err error
+		item.Card, item.Log, err = s.reviewCard(card, rating, now, fuzz, counter)
+		if err != nil {
+			return nil, err
+		}
+		item.Interval = item.Card.Due.Sub(now)
+	}
+
+	return preview, nil
+}
@@ -0,0 +1,137 @@
+package fsrs
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreview(t *testing.T) {
+	scheduler := mustNewScheduler(WithEnableFuzzing(false))
+	now := time.Date(2022, time.November, 29, 12, 30, 0, 0, time.UTC)
+
+	card, _ := reviewHistory(scheduler, NewEmptyCard(1), []Rating{Good, Good, Good}, now)
+	now = card.Due
+
+	preview := scheduler.Preview(card, now)
+	for _, rating := range []Rating{Again, Hard, Good, Easy} {
+		item := preview.Item(rating)
+		expected, expectedLog := scheduler.ReviewCardWithLog(card, rating, now)
+
+		assert.Equal(t, expected, item.Card)
+		assert.Equal(t, expectedLog, item.Log)
+		assert.Equal(t, expected.Due.Sub(now), item.Interval)
+	}
+
+	assert.Less(t, preview.Again.Interval, preview.Hard.Interval)
+	assert.Less(t, preview.Hard.Interval, preview.Good.Interval)
+	assert.Less(t, preview.Good.Interval, preview.Easy.Interval)
+	assert.Nil(t, preview.Item(0))
+}
+
+func TestPreviewSharedFuzz(t *testing.T) {
+	now := time.Date(2022, time.November, 29, 12, 30, 0, 0, time.UTC)
+	card, _ := reviewHistory(mustNewScheduler(WithEnableFuzzing(false)), NewEmptyCard(1), []Rating{Good, Good, Good, Good}, now)
+	now = card.Due
+
+	// with a shared draw, the fuzzed Good interval is the one a single review would get from the same source
+	preview := mustNewScheduler(WithRandomSource(rand.NewSource(42))).Preview(card, now)
+	single := mustNewScheduler(WithRandomSource(rand.NewSource(42))).ReviewCard(card, Good, now)
+	assert.Equal(t, single.Due, preview.Good.Card.Due)
+
+	single = mustNewScheduler(WithRandomSource(rand.NewSource(42))).ReviewCard(card, Easy, now)
+	assert.Equal(t, single.Due, preview.Easy.Card.Due)
+}
+
+func passingIntervalDays(preview *Preview) [3]int {
+	var days [3]int
+	for i, rating := range []Rating{Hard, Good, Easy} {
+		days[i] = int(math.Round(preview.Item(rating).Interval.Hours() / 24))
+	}
+	return days
+}
+
+func TestPassingIntervalsOrdered(t *testing.T) {
+	now := time.Date(2022, time.November, 29, 12, 30, 0, 0, time.UTC)
+
+	for seed := int64(0); seed < 200; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		scheduler := mustNewScheduler(WithRandomSource(rand.NewSource(seed)))
+
+		card := NewEmptyCard(seed)
+		reviewAt := now
+		for i := 0; i < 2+r.Intn(6) || card.State != Review; i++ {
+			card = scheduler.ReviewCard(card, Rating(2+r.Intn(3)), reviewAt)
+			reviewAt = card.Due.Add(time.Duration(r.Intn(48)) * time.Hour)
+		}
+
+		days := passingIntervalDays(scheduler.Preview(card, reviewAt))
+		assert.Less(t, days[0], days[1], "seed %d: Hard < Good", seed)
+		assert.Less(t, days[1], days[2], "seed %d: Good < Easy", seed)
+	}
+}
+
+func TestPassingIntervalsNearMaximumInterval(t *testing.T) {
+	now := time.Date(2022, time.November, 29, 12, 30, 0, 0, time.UTC)
+
+	for _, maximumInterval := range []int{1, 2, 3, 4, 10} {
+		scheduler := mustNewScheduler(WithMaximumInterval(maximumInterval), WithRandomSource(rand.NewSource(1)))
+
+		card := NewEmptyCard(1)
+		card = scheduler.ReviewCard(card, Easy, now)
+		card.Stability = 1000
+
+		days := passingIntervalDays(scheduler.Preview(card, card.Due))
+		assert.Equal(t, maximumInterval, days[2], "maximum interval %d", maximumInterval)
+		for _, d := range days {
+			assert.GreaterOrEqual(t, d, 1)
+			assert.LessOrEqual(t, d, maximumInterval)
+		}
+
+		if maximumInterval >= 3 {
+			assert.Equal(t, [3]int{maximumInterval - 2, maximumInterval - 1, maximumInterval}, days)
+		} else {
+			assert.LessOrEqual(t, days[0], days[1])
+			assert.LessOrEqual(t, days[1], days[2])
+		}
+	}
+}
+
+func TestHardIntervalBoundedByLastInterval(t *testing.T) {
+	now := time.Date(2022, time.November, 29, 12, 30, 0, 0, time.UTC)
+
+	for seed := int64(0); seed < 100; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		scheduler := mustNewScheduler(WithRandomSource(rand.NewSource(seed)))
+
+		card := NewEmptyCard(seed)
+		reviewAt := now
+		for i := 0; i < 2+r.Intn(6) || card.State != Review; i++ {
+			card = scheduler.ReviewCard(card, Rating(2+r.Intn(3)), reviewAt)
+			reviewAt = card.Due
+		}
+
+		lastInterval := int(math.Round(card.Due.Sub(*card.LastReview).Hours() / 24))
+		days := passingIntervalDays(scheduler.Preview(card, reviewAt))
+		assert.LessOrEqual(t, days[0], lastInterval, "seed %d", seed)
+	}
+
+	// a long stability would schedule Hard far beyond the last interval
+	scheduler := mustNewScheduler(WithEnableFuzzing(false))
+	card, _ := reviewHistory(scheduler, NewEmptyCard(1), []Rating{Good, Good}, now)
+	lastInterval := int(math.Round(card.Due.Sub(*card.LastReview).Hours() / 24))
+	card.Stability *= 10
+
+	days := passingIntervalDays(scheduler.Preview(card, card.Due))
+	assert.Equal(t, lastInterval, days[0])
+	assert.Less(t, days[0], days[1])
+
+	// reviewed late, the elapsed days bound Hard instead
+	late := card.Due.Add(time.Duration(3*lastInterval) * 24 * time.Hour)
+	days = passingIntervalDays(scheduler.Preview(card, late))
+	assert.Greater(t, days[0], lastInterval)
+	assert.LessOrEqual(t, days[0], 4*lastInterval)
+}
@@ -0,0 +1,136 @@
+package fsrs
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type replay struct {
+	// fuzz determines whether to fuzz the intervals of replayed reviews.
+	fuzz bool
+
+	// manualChanges determines whether to apply ReviewReset and ReviewRescheduled entries.
+	manualChanges bool
+}
+
+// ReplayOption defines the type for replay configuration functions
+type ReplayOption func(*replay) error
+
+// WithReplayFuzzing determines whether to apply random fuzz to replayed intervals, defaults to the scheduler's setting
+func WithReplayFuzzing(enable bool) ReplayOption {
+	return func(r *replay) error {
+		r.fuzz = enable
+		return nil
+	}
+}
+
+// WithReplayManualChanges determines whether to apply manual resets and reschedules found in the history, defaults to true
+func WithReplayManualChanges(enable bool) ReplayOption {
+	return func(r *replay) error {
+		r.manualChanges = enable
+		return nil
+	}
+}
+
+// ReplayCard rebuilds the card id from its review history by folding the logs, ordered by
+// ReviewDatetime, through the scheduler.
+//
+// ReviewReset entries turn the card back into a new card, keeping its Reps and Lapses, and
+// ReviewRescheduled entries move its due date to ScheduledDays after the entry, the start of that
+// day with WithDayBoundary, unless disabled with WithReplayManualChanges.
+func (s *Scheduler) ReplayCard(id int64, logs []ReviewLog, options ...ReplayOption) (*Card, error) {
+	r, err := s.newReplay(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.replayCard(id, logs, r)
+}
+
+// ReplayCards replays the review history of every card in histories, keyed by card ID.
+func (s *Scheduler) ReplayCards(histories map[int64][]ReviewLog, options ...ReplayOption) (map[int64]*Card, error) {
+	r, err := s.newReplay(options)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := make(map[int64]*Card, len(histories))
+	for id, logs := range histories {
+		card, err := s.replayCard(id, logs, r)
+		if err != nil {
+			return nil, err
+		}
+		cards[id] = card
+	}
+
+	return cards, nil
+}
+
+func (s *Scheduler) newReplay(options []ReplayOption) (*replay, error) {
+	r := &replay{
+		fuzz:          s.enableFuzzing,
+		manualChanges: true,
+	}
+
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func (s *Scheduler) replayCard(id int64, logs []ReviewLog, r *replay) (*Card, error) {
+	card := NewEmptyCard(id)
+
+	for i, log := range logs {
+		if log.CardID != id {
+			return nil, fmt.Errorf("%w log %d belongs to card %d, expected card %d", ErrInvalidReviewLog, i, log.CardID, id)
+		}
+		if i > 0 && log.ReviewDatetime.Before(logs[i-1].ReviewDatetime) {
+			return nil, fmt.Errorf("%w card %d log %d is earlier than the previous log", ErrInvalidReviewLog, id, i)
+		}
+
+		switch log.Kind {
+		case ReviewRated:
+			if log.Rating < Again || log.Rating > Easy {
+				return nil, fmt.Errorf("%w card %d log %d has rating %d", ErrInvalidReviewLog, id, i, log.Rating)
+			}
+			var fuzz func() float64
+			if r.fuzz {
+				fuzz = s.fuzzSource(card)
+			}
+
+			var err error
+			card, _, err = s.reviewCard(card, log.Rating, log.ReviewDatetime, fuzz, s.dueCounts())
+			if err != nil {
+				return nil, fmt.Errorf("%w card %d log %d: %w", ErrInvalidReviewLog, id, i, err)
+			}
+		case ReviewReset:
+			if r.manualChanges {
+				card = &Card{
+					ID:     id,
+					State:  Learning,
+					Due:    log.ReviewDatetime,
+					Reps:   card.Reps,
+					Lapses: card.Lapses,
+				}
+			}
+		case ReviewRescheduled:
+			if r.manualChanges {
+				card = card.Duplicate()
+				if s.calendarDays {
+					card.Due = s.addDays(log.ReviewDatetime, int(math.Round(log.ScheduledDays)))
+				} else {
+					card.Due = log.ReviewDatetime.Add(time.Duration(log.ScheduledDays * float64(24*time.Hour)))
+				}
+			}
+		default:
+			return nil, fmt.Errorf("%w card %d log %d has kind %d", ErrInvalidReviewLog, id, i, log.Kind)
+		}
+	}
+
+	return card, nil
+}
@@ -0,0 +1,92 @@
+package fsrs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func reviewHistory(scheduler *Scheduler, card *Card, ratings []Rating, now time.Time) (*Card, []ReviewLog) {
+	var logs []ReviewLog
+	for _, rating := range ratings {
+		var reviewLog *ReviewLog
+		card, reviewLog = scheduler.ReviewCardWithLog(card, rating, now)
+		logs = append(logs, *reviewLog)
+		now = card.Due
+	}
+	return card, logs
+}
+
+func TestReplayCard(t *testing.T) {
+	scheduler := mustNewScheduler(WithEnableFuzzing(false))
+	now := time.Date(2022, time.November, 29, 12, 30, 0, 0, time.UTC)
+
+	ratings := []Rating{Good, Good, Good, Again, Good, Hard, Easy}
+	card, logs := reviewHistory(scheduler, NewEmptyCard(7), ratings, now)
+
+	replayed, err := scheduler.ReplayCard(7, logs)
+	assert.NoError(t, err)
+	assert.Equal(t, card, replayed)
+
+	// replaying with new parameters recomputes the memory state
+	parameters := append([]float64(nil), DefaultParameters...)
+	parameters[2] = 5
+	other := mustNewScheduler(WithParameters(parameters))
+	replayed, err = other.ReplayCard(7, logs, WithReplayFuzzing(false))
+	assert.NoError(t, err)
+	assert.Equal(t, card.State, replayed.State)
+	assert.NotEqual(t, card.Stability, replayed.Stability)
+
+	cards, err := scheduler.ReplayCards(map[int64][]ReviewLog{7: logs, 8: nil})
+	assert.NoError(t, err)
+	assert.Equal(t, card, cards[7])
+	assert.Equal(t, Learning, cards[8].State)
+}
+
+func TestReplayCardManualChanges(t *testing.T) {
+	scheduler := mustNewScheduler(WithEnableFuzzing(false))
+	now := time.Date(2022, time.November, 29, 12, 30, 0, 0, time.UTC)
+
+	card, logs := reviewHistory(scheduler, NewEmptyCard(1), []Rating{Good, Good, Good}, now)
+
+	resetAt := card.Due.Add(time.Hour)
+	logs = append(logs, ReviewLog{CardID: 1, ReviewDatetime: resetAt, Kind: ReviewReset})
+
+	replayed, err := scheduler.ReplayCard(1, logs)
+	assert.NoError(t, err)
+	assert.Equal(t, Learning, replayed.State)
+	assert.Equal(t, 0, replayed.Step)
+	assert.Equal(t, float64(0), replayed.Stability)
+	assert.Equal(t, resetAt, replayed.Due)
+	assert.Nil(t, replayed.LastReview)
+
+	replayed, err = scheduler.ReplayCard(1, logs, WithReplayManualChanges(false))
+	assert.NoError(t, err)
+	assert.Equal(t, card, replayed)
+
+	rescheduledAt := card.Due.Add(time.Hour)
+	logs[len(logs)-1] = ReviewLog{CardID: 1, ReviewDatetime: rescheduledAt, ScheduledDays: 3, Kind: ReviewRescheduled}
+
+	replayed, err = scheduler.ReplayCard(1, logs)
+	assert.NoError(t, err)
+	assert.Equal(t, card.Stability, replayed.Stability)
+	assert.Equal(t, rescheduledAt.Add(72*time.Hour), replayed.Due)
+}
+
+func TestReplayCardInvalidLogs(t *testing.T) {
+	scheduler := mustNewScheduler(WithEnableFuzzing(false))
+	now := time.Date(2022, time.November, 29, 12, 30, 0, 0, time.UTC)
+
+	_, err := scheduler.ReplayCard(1, []ReviewLog{{CardID: 2, Rating: Good, ReviewDatetime: now}})
+	assert.ErrorIs(t, err, ErrInvalidReviewLog)
+
+	_, err = scheduler.ReplayCard(1, []ReviewLog{
+		{CardID: 1, Rating: Good, ReviewDatetime: now},
+		{CardID: 1, Rating: Good, ReviewDatetime: now.Add(-time.Minute)},
+	})
+	assert.ErrorIs(t, err, ErrInvalidReviewLog)
+
+	_, err = scheduler.ReplayCard(1, []ReviewLog{{CardID: 1, Rating: 0, ReviewDatetime: now}})
+	assert.ErrorIs(t, err, ErrInvalidReviewLog)
+}
@@ -0,0 +1,332 @@
+package fsrs
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Range searched by ComputeOptimalRetention, in percent.
+const (
+	minOptimalRetention = 70
+	maxOptimalRetention = 95
+
+	// optimalRetentionCoarseStep is the step of the first pass of the search, the second pass
+	// tries every percent around the best coarse retention.
+	optimalRetentionCoarseStep = 5
+)
+
+// minWorkloadProbability is the probability below which memory states are dropped from the workload estimation.
+const minWorkloadProbability = 1e-6
+
+// OptimalRetentionConfig describes the review costs and the workload used by ComputeOptimalRetention.
+type OptimalRetentionConfig struct {
+	// LearnCost is the time spent on the first review of a new card, learning steps included.
+	LearnCost time.Duration
+
+	// ReviewCost is the time spent on a successful review.
+	ReviewCost time.Duration
+
+	// RelearnCost is the time spent on a failed review, relearning steps included.
+	RelearnCost time.Duration
+
+	// DailyBudget is the time available for reviews every day.
+	DailyBudget time.Duration
+
+	// LearnSpan is the number of days over which the workload is estimated.
+	LearnSpan int
+
+	// MaximumInterval is the maximum number of days a Review-state card can be scheduled into the future.
+	MaximumInterval int
+
+	// FirstRatingProbabilities are the relative frequencies of Again, Hard, Good and Easy on the first review.
+	FirstRatingProbabilities [4]float64
+
+	// ReviewRatingProbabilities are the relative frequencies of Hard, Good and Easy on successful reviews.
+	ReviewRatingProbabilities [3]float64
+}
+
+// DefaultOptimalRetentionConfig returns an OptimalRetentionConfig with typical review costs and rating frequencies.
+func DefaultOptimalRetentionConfig() OptimalRetentionConfig {
+	return OptimalRetentionConfig{
+		LearnCost:                 20 * time.Second,
+		ReviewCost:                8 * time.Second,
+		RelearnCost:               25 * time.Second,
+		DailyBudget:               30 * time.Minute,
+		LearnSpan:                 365,
+		MaximumInterval:           36500,
+		FirstRatingProbabilities:  [4]float64{0.24, 0.094, 0.495, 0.171},
+		ReviewRatingProbabilities: [3]float64{0.224, 0.631, 0.145},
+	}
+}
+
+// OptimalRetention is the result of ComputeOptimalRetention.
+type OptimalRetention struct {
+	// Retention is the desired retention minimizing the workload per memorized card.
+	Retention float64
+
+	// NewCardsPerDay is the number of new cards per day that fits in the daily budget at Retention.
+	NewCardsPerDay float64
+
+	// Memorized is the expected number of cards memorized at the end of the learn span.
+	Memorized float64
+
+	// CostPerMemorized is the review time spent per memorized card.
+	CostPerMemorized time.Duration
+}
+
+// validate checks that the config describes a usable workload.
+func (c *OptimalRetentionConfig) validate() error {
+	var errorMessages []string
+
+	if c.LearnCost <= 0 {
+		errorMessages = append(errorMessages, fmt.Sprintf("learn cost = %v must be positive", c.LearnCost))
+	}
+	if c.ReviewCost < 0 || c.RelearnCost < 0 {
+		errorMessages = append(errorMessages, "review costs must not be negative")
+	}
+	if c.DailyBudget <= 0 {
+		errorMessages = append(errorMessages, fmt.Sprintf("daily budget = %v must be positive", c.DailyBudget))
+	}
+	if c.LearnSpan < 1 {
+		errorMessages = append(errorMessages, fmt.Sprintf("learn span = %d must be positive", c.LearnSpan))
+	}
+	if c.MaximumInterval < 1 {
+		errorMessages = append(errorMessages, fmt.Sprintf("maximum interval = %d must be positive", c.MaximumInterval))
+	}
+	if !validProbabilities(c.FirstRatingProbabilities[:]) {
+		errorMessages = append(errorMessages, "first rating probabilities must be non-negative and not all zero")
+	}
+	if !validProbabilities(c.ReviewRatingProbabilities[:]) {
+		errorMessages = append(errorMessages, "review rating probabilities must be non-negative and not all zero")
+	}
+
+	if len(errorMessages) > 0 {
+		return fmt.Errorf("%w optimal retention config:\n%s", ErrInvalidConfig, strings.Join(errorMessages, "\n"))
+	}
+
+	return nil
+}
+
+func validProbabilities(probabilities []float64) bool {
+	var sum float64
+	for _, p := range probabilities {
+		if p < 0 || math.IsNaN(p) || math.IsInf(p, 0) {
+			return false
+		}
+		sum += p
+	}
+	return sum > 0
+}
+
+// ComputeOptimalRetention searches desired retentions between 0.70 and 0.95 and returns the one that
+// minimizes the review time spent per memorized card for the given parameters.
+//
+// The workload is the expected value of the scheduler's own stability, difficulty and interval
+// formulas: a cohort of cards is followed through every review outcome for config.LearnSpan days,
+// and as many cohorts as config.DailyBudget allows are learned every day.
+func ComputeOptimalRetention(parameters []float64, config OptimalRetentionConfig) (*OptimalRetention, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	var (
+		best      *OptimalRetention
+		bestPct   int
+		evaluated = make(map[int]bool)
+	)
+
+	evaluate := func(pct int) error {
+		if evaluated[pct] {
+			return nil
+		}
+		evaluated[pct] = true
+
+		s, err := NewScheduler(
+			WithParameters(parameters),
+			WithDesiredRetention(float64(pct)/100),
+			WithMaximumInterval(config.MaximumInterval),
+			WithEnableFuzzing(false),
+		)
+		if err != nil {
+			return err
+		}
+
+		result := s.expectedWorkload(&config)
+		if best == nil || result.CostPerMemorized < best.CostPerMemorized {
+			best, bestPct = result, pct
+		}
+
+		return nil
+	}
+
+	for pct := minOptimalRetention; pct <= maxOptimalRetention; pct += optimalRetentionCoarseStep {
+		if err := evaluate(pct); err != nil {
+			return nil, err
+		}
+	}
+
+	coarse := bestPct
+	for pct := max(coarse-optimalRetentionCoarseStep+1, minOptimalRetention); pct <= min(coarse+optimalRetentionCoarseStep-1, maxOptimalRetention); pct++ {
+		if err := evaluate(pct); err != nil {
+			return nil, err
+		}
+	}
+
+	return best, nil
+}
+
+// workloadKey identifies the memory states merged by expectedWorkload.
+type workloadKey struct {
+	stability  int
+	difficulty int
+	interval   int
+}
+
+// workloadMass is the probability mass of cards sharing a workloadKey.
+type workloadMass struct {
+	probability float64
+
+	// stability and difficulty are weighted by probability.
+	stability  float64
+	difficulty float64
+}
+
+// expectedWorkload estimates the review cost and memorized cards of learning new cards every day
+// with s's desired retention.
+func (s *Scheduler) expectedWorkload(config *OptimalRetentionConfig) *OptimalRetention {
+	span := config.LearnSpan
+
+	// cost is the expected review seconds of a single card, by days since it was learned.
+	cost := make([]float64, span)
+	due := make([]map[workloadKey]*workloadMass, span)
+
+	firstRatings := normalizeProbabilities(config.FirstRatingProbabilities[:])
+	reviewRatings := normalizeProbabilities(config.ReviewRatingProbabilities[:])
+
+	// memorized is the expected retrievability of a single card summed over the span.
+	var memorized float64
+
+	schedule := func(day int, stability, difficulty, probability float64) {
+		if probability < minWorkloadProbability {
+			return
+		}
+
+		interval := s.nextInterval(stability)
+		memorized += probability * s.integrateRetrievability(float64(min(interval, span-day)), stability)
+
+		next := day + interval
+		if next >= span {
+			return
+		}
+
+		if due[next] == nil {
+			due[next] = make(map[workloadKey]*workloadMass)
+		}
+		key := workloadKey{
+			stability:  int(math.Round(math.Log(stability) * 10)),
+			difficulty: int(math.Round(difficulty * 2)),
+			interval:   interval,
+		}
+		mass, ok := due[next][key]
+		if !ok {
+			mass = &workloadMass{}
+			due[next][key] = mass
+		}
+		mass.probability += probability
+		mass.stability += probability * stability
+		mass.difficulty += probability * difficulty
+	}
+
+	for i, p := range firstRatings {
+		rating := Rating(i + 1)
+		cost[0] += p * config.LearnCost.Seconds()
+		schedule(0, s.initialStability(rating), s.initialDifficulty(rating), p)
+	}
+
+	for day := 1; day < span; day++ {
+		keys := make([]workloadKey, 0, len(due[day]))
+		for key := range due[day] {
+			keys = append(keys, key)
+		}
+		// visit the states in a fixed order so that the floating point sums are reproducible
+		sort.Slice(keys, func(i, j int) bool {
+			a, b := keys[i], keys[j]
+			if a.stability != b.stability {
+				return a.stability < b.stability
+			}
+			if a.difficulty != b.difficulty {
+				return a.difficulty < b.difficulty
+			}
+			return a.interval < b.interval
+		})
+
+		for _, key := range keys {
+			mass := due[day][key]
+			stability := mass.stability / mass.probability
+			difficulty := mass.difficulty / mass.probability
+			retrievability := s.retrievability(float64(key.interval), stability)
+
+			recalled := mass.probability * retrievability
+			forgotten := mass.probability - recalled
+
+			cost[day] += recalled*config.ReviewCost.Seconds() + forgotten*config.RelearnCost.Seconds()
+
+			for i, p := range reviewRatings {
+				rating := Rating(i + 2)
+				schedule(day,
+					s.nextStability(difficulty, stability, retrievability, rating),
+					s.nextDifficulty(difficulty, rating),
+					recalled*p)
+			}
+			schedule(day,
+				s.nextStability(difficulty, stability, retrievability, Again),
+				s.nextDifficulty(difficulty, Again),
+				forgotten)
+		}
+		due[day] = nil
+	}
+
+	// With n new cards a day, day t costs n * cumulativeCost[t], and the cohorts learned on
+	// every day of the span remember n * memorized cards at its end.
+	var (
+		cumulativeCost float64
+		peakCost       float64
+		totalCost      float64
+	)
+	for day := 0; day < span; day++ {
+		cumulativeCost += cost[day]
+		peakCost = max(peakCost, cumulativeCost)
+		totalCost += cumulativeCost
+	}
+
+	newCardsPerDay := config.DailyBudget.Seconds() / peakCost
+
+	return &OptimalRetention{
+		Retention:        s.desiredRetention,
+		NewCardsPerDay:   newCardsPerDay,
+		Memorized:        newCardsPerDay * memorized,
+		CostPerMemorized: time.Duration(totalCost / memorized * float64(time.Second)),
+	}
+}
+
+// integrateRetrievability returns the integral of the retrievability over the first days after a review.
+func (s *Scheduler) integrateRetrievability(days, stability float64) float64 {
+	exponent := s.decay + 1
+	return stability / (s.factor * exponent) * (math.Pow(1+s.factor*days/stability, exponent) - 1)
+}
+
+func normalizeProbabilities(probabilities []float64) []float64 {
+	var sum float64
+	for _, p := range probabilities {
+		sum += p
+	}
+
+	normalized := make([]float64, len(probabilities))
+	for i, p := range probabilities {
+		normalized[i] = p / sum
+	}
+	return normalized
+}
@@ -0,0 +1,50 @@
+package fsrs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeOptimalRetention(t *testing.T) {
+	config := DefaultOptimalRetentionConfig()
+	config.LearnSpan = 180
+
+	result, err := ComputeOptimalRetention(DefaultParameters, config)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, result.Retention, 0.7)
+	assert.LessOrEqual(t, result.Retention, 0.95)
+	assert.Greater(t, result.NewCardsPerDay, float64(0))
+	assert.Greater(t, result.Memorized, float64(0))
+	assert.Greater(t, result.CostPerMemorized, time.Duration(0))
+
+	// no other retention in the range is cheaper
+	for _, retention := range []float64{0.7, 0.8, 0.9, 0.95} {
+		s := mustNewScheduler(WithDesiredRetention(retention), WithEnableFuzzing(false))
+		assert.GreaterOrEqual(t, s.expectedWorkload(&config).CostPerMemorized, result.CostPerMemorized)
+	}
+
+	again, err := ComputeOptimalRetention(DefaultParameters, config)
+	assert.NoError(t, err)
+	assert.Equal(t, result, again)
+
+	// doubling the budget doubles the cards learned but not the cost per card
+	config.DailyBudget *= 2
+	doubled, err := ComputeOptimalRetention(DefaultParameters, config)
+	assert.NoError(t, err)
+	assert.Equal(t, result.Retention, doubled.Retention)
+	assert.InDelta(t, 2*result.NewCardsPerDay, doubled.NewCardsPerDay, 1e-9)
+}
+
+func TestComputeOptimalRetentionInvalid(t *testing.T) {
+	config := DefaultOptimalRetentionConfig()
+	config.DailyBudget = 0
+	config.LearnSpan = 0
+
+	_, err := ComputeOptimalRetention(DefaultParameters, config)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	_, err = ComputeOptimalRetention(DefaultParameters[:20], DefaultOptimalRetentionConfig())
+	assert.ErrorIs(t, err, ErrInvalidParam)
+}
@@ -0,0 +1,37 @@
+package fsrs
+
+import "time"
+
+// ReviewKind distinguishes ratings from manual changes in a review history.
+type ReviewKind int
+
+const (
+	ReviewRated       ReviewKind = iota // 0, the card was rated
+	ReviewReset                         // 1, the card was manually reset to a new card
+	ReviewRescheduled                   // 2, the card was manually rescheduled ScheduledDays after ReviewDatetime
+)
+
+// ReviewLog records a single review of a Card.
+//
+// ReviewLogs are the canonical input of every history based feature of the package,
+// a card's review history is its ReviewLogs ordered by ReviewDatetime.
+type ReviewLog struct {
+	CardID         int64     `json:"card_id"`
+	Rating         Rating    `json:"rating"`
+	ReviewDatetime time.Time `json:"review_datetime"`
+
+	// State is the state of the card before the review.
+	State State `json:"state"`
+
+	// ElapsedDays is the number of days since the previous review, 0 for the first review.
+	ElapsedDays float64 `json:"elapsed_days"`
+
+	// ScheduledDays is the interval in days until the card is due again.
+	ScheduledDays float64 `json:"scheduled_days"`
+
+	// Kind is ReviewRated for reviews, manual changes leave Rating unset.
+	Kind ReviewKind `json:"kind,omitempty"`
+
+	// Leech is set when the review made the card a leech, see WithLeechThreshold.
+	Leech bool `json:"leech,omitempty"`
+}
@@ -1,16 +1,23 @@
 package fsrs
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
 	"strings"
+	"sync"
 	"time"
 )
 
+// Scheduler schedules card reviews with the FSRS algorithm.
+//
+// A Scheduler is safe for concurrent use by multiple goroutines. The random source set by
+// WithRandomSource is shared by all reviews, so seeded fuzz is reproducible as long as the
+// reviews are made in the same order.
 type Scheduler struct {
 	// parameters are the model weights of the FSRS scheduler.
-	parameters []float64
+	parameters Parameters
 
 	// desiredRetention is the desired retention rate of cards scheduled with the scheduler.
 	desiredRetention float64
@@ -27,11 +34,37 @@ type Scheduler struct {
 	// enableFuzzing determines whether to apply a small amount of random 'fuzz' to calculated intervals.
 	enableFuzzing bool
 
+	// fuzz describes how much intervals are fuzzed.
+	fuzz FuzzConfig
+
+	// dueCounter reports the cards due on a day for load balancing, nil disables load balancing.
+	dueCounter DueCounter
+
+	// easyDays is the relative workload of each time.Weekday, nil disables easy days.
+	easyDays []float64
+
+	// location is the time zone of the weekdays and the day boundary, nil means the time zone of the review datetime.
+	location *time.Location
+
+	// leechThreshold is the number of lapses at which a card becomes a leech, 0 disables leech detection.
+	leechThreshold int
+
+	// calendarDays determines whether days start at dayBoundary in location instead of every 24 hours from a review.
+	calendarDays bool
+
+	// dayBoundary is the hour at which a new day starts when calendarDays is set.
+	dayBoundary int
+
+	// deterministicFuzz determines whether to derive the fuzz of a review from the card instead of the random source.
+	deterministicFuzz bool
+
 	decay float64
 
 	factor float64
 
-	rand *rand.Rand
+	// randMu guards rand, which is not safe for concurrent use.
+	randMu sync.Mutex
+	rand   *rand.Rand
 }
 
 // SchedulerOption defines the type for configuration functions
@@ -40,8 +73,8 @@ type SchedulerOption func(*Scheduler) error
 // NewScheduler creates a new Scheduler instance with default values and applies optional parameters
 func NewScheduler(options ...SchedulerOption) (*Scheduler, error) {
 	// Set reasonable default values
-	var params = DefaultParameters
-	var decay = -params[20]
+	var params = parametersFromVector(DefaultParameters)
+	var decay = -params.Decay
 
 	s := &Scheduler{
 		parameters:       params,
@@ -50,45 +83,119 @@ func NewScheduler(options ...SchedulerOption) (*Scheduler, error) {
 		relearningSteps:  []time.Duration{10 * time.Minute},
 		maximumInterval:  36500,
 		enableFuzzing:    true,
+		fuzz:             DefaultFuzzConfig(),
 		decay:            decay,
 		factor:           math.Pow(0.9, 1.0/decay) - 1,
 		rand:             nil,
 	}
 
-	// Apply all optional parameters
+	// Apply all optional parameters, reporting every invalid one
+	var errs []error
 	for _, option := range options {
 		if err := option(s); err != nil {
-			return nil, err
+			errs = append(errs, err)
 		}
 	}
 
+	if err := s.validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
 	return s, nil
 }
 
+// validate checks that the options of the scheduler are within valid bounds.
+func (s *Scheduler) validate() error {
+	var errorMessages []string
+
+	if !(s.desiredRetention > 0 && s.desiredRetention < 1) {
+		errorMessages = append(errorMessages,
+			fmt.Sprintf("desired retention = %f is out of bounds: (0, 1)", s.desiredRetention))
+	}
+
+	for i, step := range s.learningSteps {
+		if step <= 0 {
+			errorMessages = append(errorMessages, fmt.Sprintf("learning steps[%d] = %v must be positive", i, step))
+		}
+	}
+
+	for i, step := range s.relearningSteps {
+		if step <= 0 {
+			errorMessages = append(errorMessages, fmt.Sprintf("relearning steps[%d] = %v must be positive", i, step))
+		}
+	}
+
+	if s.maximumInterval < 1 {
+		errorMessages = append(errorMessages, fmt.Sprintf("maximum interval = %d must be at least 1 day", s.maximumInterval))
+	}
+
+	if len(errorMessages) > 0 {
+		return fmt.Errorf("%w one or more options are out of bounds:\n%s", ErrInvalidOption, strings.Join(errorMessages, "\n"))
+	}
+
+	return nil
+}
+
 // WithRandomSource sets fuzzing random source
 func WithRandomSource(source rand.Source) SchedulerOption {
 	return func(s *Scheduler) error {
+		if source == nil {
+			return fmt.Errorf("%w random source is nil", ErrInvalidOption)
+		}
+
 		s.rand = rand.New(source)
 		return nil
 	}
 }
 
-// WithParameters sets the FSRS model weight parameters
+// WithParameters sets the FSRS model weight parameters, params is copied
 func WithParameters(params []float64) SchedulerOption {
 	return func(s *Scheduler) error {
-		err := validateParameters(params)
+		parameters, err := ParametersFromVector(params)
 		if err != nil {
 			return err
 		}
 
-		s.parameters = params
-		s.decay = -params[20]
-		s.factor = math.Pow(0.9, 1.0/s.decay) - 1
+		s.setParameters(parameters)
+
+		return nil
+	}
+}
+
+// WithNamedParameters sets the FSRS model weight parameters
+func WithNamedParameters(params Parameters) SchedulerOption {
+	return func(s *Scheduler) error {
+		if err := params.Validate(); err != nil {
+			return err
+		}
+
+		s.setParameters(params)
 
 		return nil
 	}
 }
 
+func (s *Scheduler) setParameters(params Parameters) {
+	params = params.normalized()
+	s.parameters = params
+	s.decay = -params.Decay
+	s.factor = math.Pow(0.9, 1.0/s.decay) - 1
+}
+
+// Version returns the version of the FSRS algorithm run by the scheduler, which follows the number of weights.
+func (s *Scheduler) Version() AlgorithmVersion {
+	return s.parameters.Version
+}
+
+// Parameters returns the FSRS model weight parameters of the scheduler.
+func (s *Scheduler) Parameters() Parameters {
+	return s.parameters
+}
+
 // WithDesiredRetention sets the desired retention rate
 func WithDesiredRetention(retention float64) SchedulerOption {
 	return func(s *Scheduler) error {
@@ -134,16 +241,78 @@ func WithEnableFuzzing(enable bool) SchedulerOption {
 	}
 }
 
+// WithDeterministicFuzz determines whether to derive fuzz from the card ID and last review time instead of
+// the random source, so that replays, previews and other devices agree on the fuzzed due dates
+func WithDeterministicFuzz(enable bool) SchedulerOption {
+	return func(s *Scheduler) error {
+		s.deterministicFuzz = enable
+
+		return nil
+	}
+}
+
+// WithFuzzConfig sets the fuzz ranges, threshold and minimum interval, defaults to DefaultFuzzConfig
+func WithFuzzConfig(config FuzzConfig) SchedulerOption {
+	return func(s *Scheduler) error {
+		if err := config.validate(); err != nil {
+			return err
+		}
+
+		s.fuzz = config.clone()
+
+		return nil
+	}
+}
+
+// WithLeechThreshold sets the number of lapses at which a card becomes a leech, 0 disables leech detection
+func WithLeechThreshold(lapses int) SchedulerOption {
+	return func(s *Scheduler) error {
+		if lapses < 0 {
+			return fmt.Errorf("%w leech threshold = %d must not be negative", ErrInvalidOption, lapses)
+		}
+
+		s.leechThreshold = lapses
+
+		return nil
+	}
+}
+
+// IsLeech reports whether card has lapsed at least as many times as the leech threshold.
+func (s *Scheduler) IsLeech(card *Card) bool {
+	return s.leechThreshold > 0 && card.Lapses >= s.leechThreshold
+}
+
+// isLeechLapse reports whether a card with lapses lapses just became a leech, again every half
+// threshold after it first did like in Anki.
+func (s *Scheduler) isLeechLapse(lapses int) bool {
+	if s.leechThreshold == 0 || lapses < s.leechThreshold {
+		return false
+	}
+
+	return (lapses-s.leechThreshold)%max(s.leechThreshold/2, 1) == 0
+}
+
 // validateParameters checks if the parameters are within valid bounds.
 func validateParameters(parameters []float64) error {
-	if len(parameters) != len(LowerBoundsParameters) {
-		return fmt.Errorf("%w expected %d parameters, got %d", ErrInvalidParam, len(LowerBoundsParameters), len(parameters))
+	var version AlgorithmVersion
+	switch len(parameters) {
+	case FSRS6.numWeights():
+		version = FSRS6
+	case FSRS5.numWeights():
+		version = FSRS5
+	case FSRS45.numWeights():
+		version = FSRS45
+	default:
+		return fmt.Errorf("%w expected %d, %d or %d parameters, got %d", ErrInvalidParam,
+			FSRS6.numWeights(), FSRS5.numWeights(), FSRS45.numWeights(), len(parameters))
 	}
 
+	lowerBounds, upperBounds := version.bounds()
+
 	var errorMessages []string
 	for i, param := range parameters {
-		lowerBound := LowerBoundsParameters[i]
-		upperBound := UpperBoundsParameters[i]
+		lowerBound := lowerBounds[i]
+		upperBound := upperBounds[i]
 		if param < lowerBound || param > upperBound {
 			errorMessages = append(errorMessages,
 				fmt.Sprintf("parameters[%d] = %f is out of bounds: (%f, %f)", i, param, lowerBound, upperBound))
@@ -157,71 +326,108 @@ func validateParameters(parameters []float64) error {
 	return nil
 }
 
-type SchedulerSnapshot struct {
-	// Parameters are the model weights of the FSRS scheduler.
-	Parameters []float64
-
-	// DesiredRetention is the desired retention rate of cards scheduled with the scheduler.
-	DesiredRetention float64
+// GetCardRetrievability returns the probability of recalling card at now, counting whole days
+// with WithDayBoundary.
+func (s *Scheduler) GetCardRetrievability(card *Card, now time.Time) float64 {
+	if card.LastReview == nil {
+		return 0
+	}
 
-	// LearningSteps are small time intervals that schedule cards in the Learning state.
-	LearningSteps []time.Duration
+	// Calculate elapsed days
+	elapsedDays := s.elapsedDays(*card.LastReview, now)
 
-	// RelearningSteps are small time intervals that schedule cards in the Relearning state.
-	RelearningSteps []time.Duration
+	return s.retrievability(elapsedDays, card.Stability)
+}
 
-	// MaximumInterval is the maximum number of days a Review-state card can be scheduled into the future.
-	MaximumInterval int
+func (s *Scheduler) retrievability(elapsedDays, stability float64) float64 {
+	return math.Pow(1+s.factor*elapsedDays/stability, s.decay)
+}
 
-	// EnableFuzzing determines whether to apply a small amount of random 'fuzz' to calculated intervals.
-	EnableFuzzing bool
+// ReviewCard reviews card with rating at reviewDatetime and returns the updated card.
+//
+// It panics when ReviewCardWithLog does.
+func (s *Scheduler) ReviewCard(card *Card, rating Rating, reviewDatetime time.Time) *Card {
+	card, _ = s.ReviewCardWithLog(card, rating, reviewDatetime)
+	return card
 }
 
-// Snapshot dump a scheduler snapshot
-func (s *Scheduler) Snapshot() *SchedulerSnapshot {
-	ss := &SchedulerSnapshot{}
+// ReviewCardWithLog reviews card with rating at reviewDatetime and returns the updated card
+// together with a ReviewLog describing the review.
+//
+// It panics when TryReviewCard would return an error, except that reviewDatetime may be before
+// the last review of card.
+func (s *Scheduler) ReviewCardWithLog(card *Card, rating Rating, reviewDatetime time.Time) (*Card, *ReviewLog) {
+	var fuzz func() float64
+	if s.enableFuzzing {
+		fuzz = s.fuzzSource(card)
+	}
 
-	ss.Parameters = append(ss.Parameters, s.parameters...)
-	ss.DesiredRetention = s.desiredRetention
-	ss.LearningSteps = append(ss.LearningSteps, s.learningSteps...)
-	ss.RelearningSteps = append(ss.RelearningSteps, s.relearningSteps...)
-	ss.MaximumInterval = s.maximumInterval
-	ss.EnableFuzzing = s.enableFuzzing
+	card, reviewLog, err := s.reviewCard(card, rating, reviewDatetime, fuzz, s.dueCounts())
+	if err != nil {
+		panic(err)
+	}
 
-	return ss
+	return card, reviewLog
 }
 
-func (s *Scheduler) GetCardRetrievability(card *Card, now time.Time) float64 {
-	if card.LastReview == nil {
-		return 0
+// TryReviewCard is like ReviewCardWithLog but returns an error instead of panicking when rating is
+// not one of the four ratings (ErrInvalidRating), the card has an unknown State (ErrInvalidState),
+// only one of its Stability and Difficulty is set (ErrInconsistentMemoryState), or reviewDatetime
+// is before its last review (ErrReviewBeforeLastReview).
+func (s *Scheduler) TryReviewCard(card *Card, rating Rating, reviewDatetime time.Time) (*Card, *ReviewLog, error) {
+	if err := validateReviewDatetime(card, reviewDatetime); err != nil {
+		return nil, nil, err
 	}
 
-	// Calculate elapsed days
-	elapsedDays := now.Sub(*card.LastReview).Hours() / 24
-	stability := card.Stability
-
-	// Calculate retrievability
+	var fuzz func() float64
+	if s.enableFuzzing {
+		fuzz = s.fuzzSource(card)
+	}
 
-	return math.Pow(1+s.factor*elapsedDays/stability, s.decay)
+	return s.reviewCard(card, rating, reviewDatetime, fuzz, s.dueCounts())
 }
 
-func (s *Scheduler) ReviewCard(card *Card, rating Rating, reviewDatetime time.Time) *Card {
+// reviewCard reviews card, fuzz draws the random value of the fuzzed interval, nil disables fuzzing,
+// and counter reports the cards due on a day to the load balancer, nil disables load balancing.
+func (s *Scheduler) reviewCard(card *Card, rating Rating, reviewDatetime time.Time, fuzz func() float64, counter DueCounter) (*Card, *ReviewLog, error) {
 	var (
 		daysSinceLastReview float64
-		hasLastReview       bool
+		shortTerm           bool
 		nextInterval        time.Duration
+		fuzzed              bool
 	)
 
-	assertCard(card)
+	if err := validateReview(card, rating); err != nil {
+		return nil, nil, err
+	}
 
 	if card.LastReview != nil {
-		hasLastReview = true
-		daysSinceLastReview = reviewDatetime.Sub(*card.LastReview).Hours() / 24
+		daysSinceLastReview = s.elapsedDays(*card.LastReview, reviewDatetime)
+		// FSRS-4.5 has no short-term stability
+		shortTerm = daysSinceLastReview < 1 && s.parameters.Version != FSRS45
+	}
+
+	reviewLog := &ReviewLog{
+		CardID:         card.ID,
+		Rating:         rating,
+		ReviewDatetime: reviewDatetime,
+		State:          card.State,
+		ElapsedDays:    daysSinceLastReview,
+	}
+
+	if fuzz != nil {
+		fuzz = sharedFuzz(fuzz)
 	}
 
 	// copy
 	card = card.Duplicate()
 
+	card.Reps++
+	if card.State == Review && rating == Again {
+		card.Lapses++
+		reviewLog.Leech = s.isLeechLapse(card.Lapses)
+	}
+
 	switch card.State {
 	case Learning, Relearning:
 		steps := s.learningSteps
@@ -233,7 +439,7 @@ func (s *Scheduler) ReviewCard(card *Card, rating Rating, reviewDatetime time.Ti
 		if card.Stability == 0 && card.Difficulty == 0 {
 			card.Stability = s.initialStability(rating)
 			card.Difficulty = s.initialDifficulty(rating)
-		} else if hasLastReview && daysSinceLastReview < 1 {
+		} else if shortTerm {
 			card.Stability = s.shortTermStability(card.Stability, rating)
 			card.Difficulty = s.nextDifficulty(card.Difficulty, rating)
 		} else {
@@ -287,12 +493,23 @@ func (s *Scheduler) ReviewCard(card *Card, rating Rating, reviewDatetime time.Ti
 
 	case Review:
 		// Update stability and difficulty
-		if hasLastReview && daysSinceLastReview < 1 {
-			card.Stability = s.shortTermStability(card.Stability, rating)
-		} else {
-			retrievability := s.GetCardRetrievability(card, reviewDatetime)
-			card.Stability = s.nextStability(card.Difficulty, card.Stability, retrievability, rating)
+		// The stabilities of Hard, Good and Easy are all needed to keep their intervals ordered
+		retrievability := s.GetCardRetrievability(card, reviewDatetime)
+		reviewStability := func(r Rating) float64 {
+			if shortTerm {
+				return s.shortTermStability(card.Stability, r)
+			}
+			return s.nextStability(card.Difficulty, card.Stability, retrievability, r)
+		}
+
+		passingStabilities := [3]float64{reviewStability(Hard), reviewStability(Good), reviewStability(Easy)}
+
+		// the interval the card was last scheduled for, or the elapsed days when reviewed late
+		var lastInterval float64
+		if card.LastReview != nil {
+			lastInterval = max(s.elapsedDays(*card.LastReview, card.Due), daysSinceLastReview)
 		}
+		card.Stability = reviewStability(rating)
 		card.Difficulty = s.nextDifficulty(card.Difficulty, rating)
 
 		// Calculate next interval
@@ -309,24 +526,31 @@ func (s *Scheduler) ReviewCard(card *Card, rating Rating, reviewDatetime time.Ti
 				nextInterval = s.relearningSteps[card.Step]
 			}
 		default: // Hard, Good, Easy
-			nextIntervalDays := s.nextInterval(card.Stability)
+			nextIntervalDays := s.passingInterval(rating, passingStabilities, lastInterval, reviewDatetime, fuzz, counter)
 			nextInterval = time.Duration(nextIntervalDays) * 24 * time.Hour
+			fuzzed = true
 		}
 
 	default:
-		panic(fmt.Sprintf("unknown state %v card id %v", card.ID, card.State))
+		return nil, nil, fmt.Errorf("%w card %d has state %d", ErrInvalidState, card.ID, card.State)
 	}
 
-	if s.enableFuzzing && card.State == Review {
-		nextInterval = s.getFuzzedInterval(nextInterval)
+	if fuzz != nil && card.State == Review && !fuzzed {
+		nextInterval = s.getFuzzedInterval(nextInterval, reviewDatetime, fuzz, counter)
 	}
 
 	// Finalize card update
-	card.Due = reviewDatetime.Add(nextInterval)
+	if card.State == Review {
+		card.Due = s.addDays(reviewDatetime, int(nextInterval/(24*time.Hour)))
+	} else {
+		card.Due = reviewDatetime.Add(nextInterval)
+	}
 	lastReviewTime := reviewDatetime
 	card.LastReview = &lastReviewTime
 
-	return card
+	reviewLog.ScheduledDays = nextInterval.Hours() / 24
+
+	return card, reviewLog, nil
 }
 
 func (s *Scheduler) clampDdifficulty(difficulty float64) float64 {
@@ -338,16 +562,18 @@ func (s *Scheduler) clampStability(stability float64) float64 {
 }
 
 func (s *Scheduler) initialStability(rating Rating) float64 {
-	return s.clampStability(s.parameters[rating-1])
+	return s.clampStability(s.parameters.InitialStability[rating-1])
 }
 
 func (s *Scheduler) initialDifficulty(rating Rating) float64 {
-	var (
-		p4 = s.parameters[4]
-		p5 = s.parameters[5]
-	)
+	p := &s.parameters
 
-	difficulty := p4 - math.Pow(math.E, p5*(float64(rating)-1)) + 1
+	var difficulty float64
+	if p.Version == FSRS45 {
+		difficulty = p.InitialDifficulty - (float64(rating)-3)*p.InitialDifficultyScale
+	} else {
+		difficulty = p.InitialDifficulty - math.Pow(math.E, p.InitialDifficultyScale*(float64(rating)-1)) + 1
+	}
 
 	return s.clampDdifficulty(difficulty)
 }
@@ -366,11 +592,14 @@ func (s *Scheduler) nextInterval(stability float64) (days int) {
 }
 
 func (s *Scheduler) shortTermStability(stability float64, rating Rating) float64 {
-	p17 := s.parameters[17]
-	p18 := s.parameters[18]
-	p19 := s.parameters[19]
+	p := &s.parameters
+
+	shortTermStabilityIncrease := math.Pow(math.E, p.ShortTermRatingScale*(float64(rating)-3+p.ShortTermRatingOffset))
+	if p.Version == FSRS5 {
+		return s.clampStability(stability * shortTermStabilityIncrease)
+	}
 
-	shortTermStabilityIncrease := math.Pow(math.E, p17*(float64(rating)-3+p18)) * math.Pow(stability, -p19)
+	shortTermStabilityIncrease *= math.Pow(stability, -p.ShortTermStabilityDecay)
 
 	if rating == Good || rating == Easy {
 		shortTermStabilityIncrease = max(shortTermStabilityIncrease, 1.0)
@@ -380,21 +609,25 @@ func (s *Scheduler) shortTermStability(stability float64, rating Rating) float64
 }
 
 func (s *Scheduler) nextDifficulty(difficulty float64, rating Rating) float64 {
-	p6 := s.parameters[6]
-	p7 := s.parameters[7]
+	p := &s.parameters
 
 	linearDamping := func(deltaDifficulty, difficulty float64) float64 {
 		return (10.0 - difficulty) * deltaDifficulty / 9.0
 	}
 
 	meanReversion := func(arg1, arg2 float64) float64 {
-		return p7*arg1 + (1-p7)*arg2
+		return p.DifficultyMeanReversion*arg1 + (1-p.DifficultyMeanReversion)*arg2
 	}
 
 	arg1 := s.initialDifficulty(Easy)
-	deltaDifficulty := -(p6 * (float64(rating) - 3))
+	deltaDifficulty := -(p.DifficultyDelta * (float64(rating) - 3))
 	arg2 := difficulty + linearDamping(deltaDifficulty, difficulty)
 
+	if p.Version == FSRS45 {
+		arg1 = s.initialDifficulty(Good)
+		arg2 = difficulty + deltaDifficulty
+	}
+
 	return s.clampDdifficulty(meanReversion(arg1, arg2))
 }
 
@@ -412,81 +645,190 @@ func (s *Scheduler) nextStability(difficulty, stability, retrievability float64,
 }
 
 func (s *Scheduler) nextForgetStability(difficulty, stability, retrievability float64) float64 {
-	p11 := s.parameters[11]
-	p12 := s.parameters[12]
-	p13 := s.parameters[13]
-	p14 := s.parameters[14]
-	p17 := s.parameters[17]
-	p18 := s.parameters[18]
+	p := &s.parameters
 
-	longTermParams := p11 *
-		math.Pow(difficulty, -p12) *
-		(math.Pow(stability+1, p13) - 1) *
-		math.Pow(math.E, (1-retrievability)*p14)
+	longTermParams := p.ForgetStabilityScale *
+		math.Pow(difficulty, -p.ForgetDifficultyDecay) *
+		(math.Pow(stability+1, p.ForgetStabilityExponent) - 1) *
+		math.Pow(math.E, (1-retrievability)*p.ForgetRetrievabilityFactor)
 
-	shortTermParams := stability / math.Pow(math.E, p17*p18)
+	if p.Version == FSRS45 {
+		return longTermParams
+	}
+
+	shortTermParams := stability / math.Pow(math.E, p.ShortTermRatingScale*p.ShortTermRatingOffset)
 
 	return min(longTermParams, shortTermParams)
 }
 
 func (s *Scheduler) nextRecallStability(difficulty, stability, retrievability float64, rating Rating) float64 {
-	p8 := s.parameters[8]
-	p9 := s.parameters[9]
-	p10 := s.parameters[10]
-	p15 := s.parameters[15]
-	p16 := s.parameters[16]
+	p := &s.parameters
 
 	hardPenalty := 1.0
 	if rating == Hard {
-		hardPenalty = p15
+		hardPenalty = p.HardPenalty
 	}
 
 	easyBonus := 1.0
 	if rating == Easy {
-		easyBonus = p16
+		easyBonus = p.EasyBonus
 	}
 
 	return stability * (1 +
-		math.Pow(math.E, p8)*
+		math.Pow(math.E, p.RecallStabilityScale)*
 			(11-difficulty)*
-			math.Pow(stability, -p9)*
-			(math.Pow(math.E, (1-retrievability)*p10)-1)*
+			math.Pow(stability, -p.RecallStabilityDecay)*
+			(math.Pow(math.E, (1-retrievability)*p.RecallRetrievabilityFactor)-1)*
 			hardPenalty*
 			easyBonus)
 }
 
-func (s *Scheduler) getFuzzedInterval(interval time.Duration) time.Duration {
+// fuzzSource returns the source of the fuzz of reviewing card.
+func (s *Scheduler) fuzzSource(card *Card) func() float64 {
+	if !s.deterministicFuzz {
+		return s.randomFloat
+	}
+
+	r := cardFuzz(card)
+	return func() float64 {
+		return r
+	}
+}
+
+// cardFuzz derives a value in [0, 1) from the ID and last review time of card.
+func cardFuzz(card *Card) float64 {
+	var lastReview int64
+	if card.LastReview != nil {
+		lastReview = card.LastReview.UnixNano()
+	}
+
+	h := splitmix64(splitmix64(uint64(card.ID)) ^ uint64(lastReview))
+	return float64(h>>11) / (1 << 53)
+}
+
+// splitmix64 is the finalizer of the SplitMix64 generator, a fast bijective integer hash.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+func (s *Scheduler) randomFloat() float64 {
+	if s.rand == nil {
+		return rand.Float64()
+	}
+
+	s.randMu.Lock()
+	defer s.randMu.Unlock()
+
+	return s.rand.Float64()
+}
+
+// sharedFuzz returns a fuzz function drawing from fuzz once and returning the same value afterwards.
+func sharedFuzz(fuzz func() float64) func() float64 {
+	var (
+		r     float64
+		drawn bool
+	)
+
+	return func() float64 {
+		if !drawn {
+			r, drawn = fuzz(), true
+		}
+		return r
+	}
+}
+
+func (s *Scheduler) getFuzzedInterval(interval time.Duration, reviewDatetime time.Time, fuzz func() float64, counter DueCounter) time.Duration {
 	intervalDays := float64(interval.Hours() / 24)
 
-	if intervalDays < 2.5 {
+	if intervalDays < s.fuzz.Threshold {
 		return interval
 	}
 
+	return time.Duration(s.getFuzzedDays(intervalDays, reviewDatetime, fuzz, counter)) * 24 * time.Hour
+}
+
+func (s *Scheduler) getFuzzedDays(intervalDays float64, reviewDatetime time.Time, fuzz func() float64, counter DueCounter) int {
 	minIvl, maxIvl := s.getFuzzRange(intervalDays)
 
-	var r float64
-	if s.rand == nil {
-		r = rand.Float64()
-	} else {
-		r = s.rand.Float64()
+	return s.pickDays(minIvl, maxIvl, reviewDatetime, fuzz(), counter)
+}
+
+// passingInterval returns the interval in days of rating a Review-state card Hard, Good or Easy
+// given the stabilities each of them results in and the last interval of the card in days, 0 when
+// unknown.
+//
+// The intervals are strictly increasing, Hard < Good < Easy, unless the maximum interval is
+// shorter than 3 days; rating higher never schedules the card earlier. Like Anki, Hard is never
+// longer than the last interval, so a card recalled with difficulty does not grow its interval.
+//
+// The order is enforced on the unfuzzed intervals first, then overlapping fuzz ranges are split
+// between the intervals, so the day of rating is picked within its own range only.
+func (s *Scheduler) passingInterval(rating Rating, stabilities [3]float64, lastInterval float64, reviewDatetime time.Time, fuzz func() float64, counter DueCounter) int {
+	hardMax := s.maximumInterval
+	if lastInterval > 0 {
+		hardMax = min(hardMax, max(int(math.Round(lastInterval)), 1))
+	}
+
+	var intervals [3]int
+	for i, stability := range stabilities {
+		intervals[i] = s.nextInterval(stability)
+	}
+
+	intervals[0] = min(max(intervals[0], 1), hardMax)
+	intervals[1] = max(intervals[1], intervals[0]+1)
+	intervals[2] = max(intervals[2], intervals[1]+1)
+
+	intervals[2] = min(intervals[2], s.maximumInterval)
+	intervals[1] = min(intervals[1], max(intervals[2]-1, 1))
+	intervals[0] = min(intervals[0], max(intervals[1]-1, 1))
+
+	i := rating - Hard
+	if fuzz == nil || float64(intervals[i]) < s.fuzz.Threshold {
+		return intervals[i]
+	}
+
+	minIvl, maxIvl := s.getFuzzRange(float64(intervals[i]))
+	if i == 0 {
+		maxIvl = min(maxIvl, hardMax)
+	}
+	if i > 0 && intervals[i-1] < intervals[i] {
+		minIvl = max(minIvl, (intervals[i-1]+intervals[i])/2+1)
+	}
+	if i < 2 && intervals[i] < intervals[i+1] {
+		maxIvl = min(maxIvl, (intervals[i]+intervals[i+1])/2)
+	}
+
+	return s.pickDays(minIvl, maxIvl, reviewDatetime, fuzz(), counter)
+}
+
+// pickDays picks an interval between minIvl and maxIvl days with r in [0, 1).
+//
+// Unlike py-fsrs, the rounded pick is clamped to maxIvl so that it never leaves the fuzz range.
+func (s *Scheduler) pickDays(minIvl, maxIvl int, reviewDatetime time.Time, r float64, counter DueCounter) int {
+	if minIvl == maxIvl {
+		return minIvl
+	}
+
+	if counter != nil || s.easyDays != nil {
+		return s.balancedDays(minIvl, maxIvl, reviewDatetime, r, counter)
 	}
-	fuzzedDays := float64(minIvl) + r*(float64(maxIvl-minIvl+1))
-	fuzzedDays = math.Round(fuzzedDays)
-	fuzzedDaysClamped := min(fuzzedDays, float64(s.maximumInterval))
 
-	return time.Duration(fuzzedDaysClamped) * 24 * time.Hour
+	return min(int(math.Round(float64(minIvl)+r*float64(maxIvl-minIvl+1))), maxIvl)
 }
 
 func (s *Scheduler) getFuzzRange(days float64) (int, int) {
 	delta := 1.0
-	for _, fr := range FuzzRanges {
+	for _, fr := range s.fuzz.Ranges {
 		delta += fr.Factor * max(0.0, min(days, fr.End)-fr.Start)
 	}
 
 	minIvl := int(math.Round(days - delta))
 	maxIvl := int(math.Round(days + delta))
 
-	minIvl = max(2, minIvl)
+	minIvl = max(s.fuzz.Minimum, minIvl)
 	maxIvl = min(maxIvl, s.maximumInterval)
 	minIvl = min(minIvl, maxIvl)
 
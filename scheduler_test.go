@@ -3,6 +3,7 @@ package fsrs
 import (
 	"math"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -276,8 +277,9 @@ func TestFuzzing(t *testing.T) {
 	prevDue = card.Due
 	card = scheduler.ReviewCard(card, Good, prevDue)
 
+	// the fuzz window of the Good interval is [13, 19] days
 	intervalDays := int(math.Round(card.Due.Sub(prevDue).Hours() / 24))
-	assert.Equal(t, 20, intervalDays)
+	assert.Equal(t, 19, intervalDays)
 }
 
 func TestNoLearningSteps(t *testing.T) {
@@ -558,7 +560,7 @@ func TestCustomSchedulerArgs(t *testing.T) {
 		WithMaximumInterval(maximumInterval2),
 	)
 
-	assert.Equal(t, parameters2, scheduler2.parameters, "Parameters should match the provided values")
+	assert.Equal(t, parameters2, scheduler2.parameters.Vector(), "Parameters should match the provided values")
 	assert.Equal(t, desiredRetention2, scheduler2.desiredRetention, "Desired retention should match the provided value")
 	assert.Equal(t, maximumInterval2, scheduler2.maximumInterval, "Maximum interval should match the provided value")
 
@@ -579,7 +581,7 @@ func TestSchedulerSnapshot(t *testing.T) {
 
 	snapshot := scheduler.Snapshot()
 
-	assert.Equal(t, scheduler.parameters, snapshot.Parameters)
+	assert.Equal(t, scheduler.parameters.Vector(), snapshot.Parameters)
 	assert.Equal(t, scheduler.desiredRetention, snapshot.DesiredRetention)
 	assert.Equal(t, scheduler.learningSteps, snapshot.LearningSteps)
 	assert.Equal(t, scheduler.relearningSteps, snapshot.RelearningSteps)
@@ -587,3 +589,184 @@ func TestSchedulerSnapshot(t *testing.T) {
 	assert.Equal(t, scheduler.enableFuzzing, snapshot.EnableFuzzing)
 
 }
+
+func TestReviewCardWithLog(t *testing.T) {
+	scheduler := mustNewScheduler(WithEnableFuzzing(false))
+
+	card := NewEmptyCard(42)
+	firstReview := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+
+	card, reviewLog := scheduler.ReviewCardWithLog(card, Good, firstReview)
+	assert.Equal(t, int64(42), reviewLog.CardID)
+	assert.Equal(t, Good, reviewLog.Rating)
+	assert.Equal(t, firstReview, reviewLog.ReviewDatetime)
+	assert.Equal(t, Learning, reviewLog.State)
+	assert.Equal(t, float64(0), reviewLog.ElapsedDays)
+	assert.InDelta(t, card.Due.Sub(firstReview).Hours()/24, reviewLog.ScheduledDays, 1e-9)
+
+	secondReview := firstReview.Add(36 * time.Hour)
+	card, reviewLog = scheduler.ReviewCardWithLog(card, Good, secondReview)
+	assert.Equal(t, Learning, reviewLog.State)
+	assert.InDelta(t, 1.5, reviewLog.ElapsedDays, 1e-9)
+	assert.Equal(t, Review, card.State)
+	assert.InDelta(t, card.Due.Sub(secondReview).Hours()/24, reviewLog.ScheduledDays, 1e-9)
+}
+
+func TestTryReviewCard(t *testing.T) {
+	scheduler := mustNewScheduler()
+	now := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+
+	card, reviewLog, err := scheduler.TryReviewCard(NewEmptyCard(1), Good, now)
+	assert.NoError(t, err)
+	assert.Equal(t, Good, reviewLog.Rating)
+
+	for _, rating := range []Rating{0, 5, -1} {
+		_, _, err = scheduler.TryReviewCard(card, rating, now)
+		assert.ErrorIs(t, err, ErrInvalidRating)
+	}
+
+	invalidState := card.Duplicate()
+	invalidState.State = 0
+	_, _, err = scheduler.TryReviewCard(invalidState, Good, now)
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	inconsistent := card.Duplicate()
+	inconsistent.Difficulty = 0
+	_, _, err = scheduler.TryReviewCard(inconsistent, Good, now)
+	assert.ErrorIs(t, err, ErrInconsistentMemoryState)
+
+	_, _, err = scheduler.TryReviewCard(card, Good, now.Add(-time.Minute))
+	assert.ErrorIs(t, err, ErrReviewBeforeLastReview)
+
+	_, err = scheduler.TryPreview(card, now.Add(-time.Minute))
+	assert.ErrorIs(t, err, ErrReviewBeforeLastReview)
+
+	_, err = scheduler.TryPreview(invalidState, now)
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	assert.Panics(t, func() { scheduler.ReviewCard(card, 5, now) })
+}
+
+func TestInvalidSchedulerOptions(t *testing.T) {
+	for _, option := range []SchedulerOption{
+		WithDesiredRetention(0),
+		WithDesiredRetention(1),
+		WithDesiredRetention(1.5),
+		WithDesiredRetention(math.NaN()),
+		WithLearningSteps([]time.Duration{time.Minute, -time.Minute}),
+		WithRelearningSteps([]time.Duration{0}),
+		WithMaximumInterval(0),
+		WithRandomSource(nil),
+	} {
+		_, err := NewScheduler(option)
+		assert.ErrorIs(t, err, ErrInvalidOption)
+	}
+
+	// every failure is reported
+	_, err := NewScheduler(
+		WithParameters(DefaultParameters[:20]),
+		WithDesiredRetention(1.5),
+		WithLearningSteps([]time.Duration{-time.Minute}),
+		WithMaximumInterval(-1),
+	)
+	assert.ErrorIs(t, err, ErrInvalidParam)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+	assert.Contains(t, err.Error(), "desired retention")
+	assert.Contains(t, err.Error(), "learning steps[0]")
+	assert.Contains(t, err.Error(), "maximum interval")
+}
+
+func TestConcurrentReviews(t *testing.T) {
+	scheduler := mustNewScheduler(WithRandomSource(rand.NewSource(1)))
+	now := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+
+			card := NewEmptyCard(id)
+			reviewAt := now
+			for j := 0; j < 20; j++ {
+				scheduler.Preview(card, reviewAt)
+				card = scheduler.ReviewCard(card, Good, reviewAt)
+				reviewAt = card.Due
+			}
+			assert.Equal(t, Review, card.State)
+		}(int64(i))
+	}
+	wg.Wait()
+}
+
+func TestDeterministicFuzz(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+	ratings := []Rating{Good, Good, Good, Hard, Good, Easy, Good}
+
+	review := func(scheduler *Scheduler, id int64) (*Card, []ReviewLog) {
+		return reviewHistory(scheduler, NewEmptyCard(id), ratings, now)
+	}
+
+	// two servers with independent random sources agree on the due dates
+	first := mustNewScheduler(WithDeterministicFuzz(true), WithRandomSource(rand.NewSource(1)))
+	second := mustNewScheduler(WithDeterministicFuzz(true), WithRandomSource(rand.NewSource(2)))
+	card, logs := review(first, 1)
+	other, _ := review(second, 1)
+	assert.Equal(t, card, other)
+
+	// the intervals are fuzzed differently for different cards
+	dues := make(map[time.Time]bool)
+	for id := int64(1); id <= 20; id++ {
+		c, _ := review(first, id)
+		dues[c.Due] = true
+	}
+	assert.Greater(t, len(dues), 1)
+
+	// replay and preview agree with the committed review
+	replayed, err := second.ReplayCard(1, logs)
+	assert.NoError(t, err)
+	assert.Equal(t, card, replayed)
+
+	reviewAt := card.Due.Add(time.Hour)
+	preview := first.Preview(card, reviewAt)
+	for _, rating := range []Rating{Hard, Good, Easy} {
+		assert.Equal(t, second.ReviewCard(card, rating, reviewAt), preview.Item(rating).Card)
+	}
+}
+
+func TestRepsAndLapses(t *testing.T) {
+	scheduler := mustNewScheduler(WithEnableFuzzing(false), WithRelearningSteps(nil))
+	now := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+
+	// Again while learning is not a lapse
+	card, _ := reviewHistory(scheduler, NewEmptyCard(1), []Rating{Again, Good, Good, Good, Again, Hard, Again}, now)
+	assert.Equal(t, 7, card.Reps)
+	assert.Equal(t, 2, card.Lapses)
+
+	duplicate := card.Duplicate()
+	assert.Equal(t, card, duplicate)
+}
+
+func TestLeechThreshold(t *testing.T) {
+	scheduler := mustNewScheduler(WithEnableFuzzing(false), WithRelearningSteps(nil), WithLeechThreshold(4))
+	now := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+
+	card, _ := reviewHistory(scheduler, NewEmptyCard(1), []Rating{Easy}, now)
+
+	var leeches []int
+	for lapse := 1; lapse <= 8; lapse++ {
+		var log *ReviewLog
+		card, log = scheduler.ReviewCardWithLog(card, Again, card.Due)
+		assert.Equal(t, lapse >= 4, scheduler.IsLeech(card))
+		if log.Leech {
+			leeches = append(leeches, card.Lapses)
+		}
+	}
+	// a leech is reported at the threshold and every half threshold after it
+	assert.Equal(t, []int{4, 6, 8}, leeches)
+
+	assert.False(t, mustNewScheduler().IsLeech(card))
+
+	_, err := NewScheduler(WithLeechThreshold(-1))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
@@ -0,0 +1,332 @@
+// Package simulator forecasts the review workload of a fsrs.Scheduler configuration.
+package simulator
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/patricksuo/fsrs"
+)
+
+var (
+	ErrInvalidOption = errors.New("Simulator options invalid")
+)
+
+type simulator struct {
+	// start is the beginning of the first simulated day.
+	start time.Time
+
+	// deckSize is the number of new cards available, 0 means unlimited.
+	deckSize int
+
+	// newCardsPerDay is the maximum number of new cards learned every day.
+	newCardsPerDay int
+
+	// reviewLimit is the maximum number of Review-state cards reviewed every day, 0 means unlimited.
+	reviewLimit int
+
+	// learnCost, reviewCost and relearnCost are the time spent on the first review of a card,
+	// on a successful review and on a failed review.
+	learnCost   time.Duration
+	reviewCost  time.Duration
+	relearnCost time.Duration
+
+	// firstRatingProbabilities are the relative frequencies of Again, Hard, Good and Easy on the first review.
+	firstRatingProbabilities [4]float64
+
+	// reviewRatingProbabilities are the relative frequencies of Hard, Good and Easy on successful reviews.
+	reviewRatingProbabilities [3]float64
+
+	rand *rand.Rand
+}
+
+// Option defines the type for simulator configuration functions
+type Option func(*simulator) error
+
+// WithStart sets the beginning of the first simulated day
+func WithStart(start time.Time) Option {
+	return func(s *simulator) error {
+		s.start = start
+		return nil
+	}
+}
+
+// WithDeckSize sets the number of new cards available, 0 means unlimited
+func WithDeckSize(size int) Option {
+	return func(s *simulator) error {
+		if size < 0 {
+			return fmt.Errorf("%w deck size must not be negative, got %d", ErrInvalidOption, size)
+		}
+
+		s.deckSize = size
+
+		return nil
+	}
+}
+
+// WithNewCardsPerDay sets the maximum number of new cards learned every day
+func WithNewCardsPerDay(limit int) Option {
+	return func(s *simulator) error {
+		if limit < 0 {
+			return fmt.Errorf("%w new cards per day must not be negative, got %d", ErrInvalidOption, limit)
+		}
+
+		s.newCardsPerDay = limit
+
+		return nil
+	}
+}
+
+// WithReviewLimit sets the maximum number of Review-state cards reviewed every day, 0 means unlimited
+func WithReviewLimit(limit int) Option {
+	return func(s *simulator) error {
+		if limit < 0 {
+			return fmt.Errorf("%w review limit must not be negative, got %d", ErrInvalidOption, limit)
+		}
+
+		s.reviewLimit = limit
+
+		return nil
+	}
+}
+
+// WithCosts sets the time spent on the first review of a card, on a successful review and on a failed review
+func WithCosts(learn, review, relearn time.Duration) Option {
+	return func(s *simulator) error {
+		if learn < 0 || review < 0 || relearn < 0 {
+			return fmt.Errorf("%w costs must not be negative, got %v, %v, %v", ErrInvalidOption, learn, review, relearn)
+		}
+
+		s.learnCost, s.reviewCost, s.relearnCost = learn, review, relearn
+
+		return nil
+	}
+}
+
+// WithFirstRatingProbabilities sets the relative frequencies of Again, Hard, Good and Easy on the first review
+func WithFirstRatingProbabilities(probabilities [4]float64) Option {
+	return func(s *simulator) error {
+		if !validProbabilities(probabilities[:]) {
+			return fmt.Errorf("%w first rating probabilities must be non-negative and not all zero, got %v", ErrInvalidOption, probabilities)
+		}
+
+		s.firstRatingProbabilities = probabilities
+
+		return nil
+	}
+}
+
+// WithReviewRatingProbabilities sets the relative frequencies of Hard, Good and Easy on successful reviews
+func WithReviewRatingProbabilities(probabilities [3]float64) Option {
+	return func(s *simulator) error {
+		if !validProbabilities(probabilities[:]) {
+			return fmt.Errorf("%w review rating probabilities must be non-negative and not all zero, got %v", ErrInvalidOption, probabilities)
+		}
+
+		s.reviewRatingProbabilities = probabilities
+
+		return nil
+	}
+}
+
+// WithRandomSource sets the random source used to sample ratings
+func WithRandomSource(source rand.Source) Option {
+	return func(s *simulator) error {
+		if source == nil {
+			return fmt.Errorf("%w random source is nil", ErrInvalidOption)
+		}
+
+		s.rand = rand.New(source)
+		return nil
+	}
+}
+
+func validProbabilities(probabilities []float64) bool {
+	var sum float64
+	for _, p := range probabilities {
+		if !(p >= 0) {
+			return false
+		}
+		sum += p
+	}
+	return sum > 0
+}
+
+// DayStats is the workload of a simulated day.
+type DayStats struct {
+	// Start is the beginning of the day.
+	Start time.Time
+
+	// NewCards is the number of cards reviewed for the first time.
+	NewCards int
+
+	// Reviews is the number of reviews of previously learned cards, learning steps included.
+	Reviews int
+
+	// Lapses is the number of Review-state cards rated Again.
+	Lapses int
+
+	// TimeSpent is the time spent on all reviews of the day.
+	TimeSpent time.Duration
+
+	// Memorized is the sum of the retrievability of every learned card at the end of the day.
+	Memorized float64
+}
+
+// Simulate drives scheduler through days simulated days of reviews and returns the workload of each day.
+//
+// Every day up to the new card limit is learned and due cards are reviewed in due order, Review-state
+// cards past the review limit are postponed to the next day. Recall is sampled from
+// scheduler.GetCardRetrievability at review time. Runs are reproducible when both the simulator and
+// the scheduler use a seeded random source, or fuzzing is disabled.
+func Simulate(scheduler *fsrs.Scheduler, days int, options ...Option) ([]DayStats, error) {
+	s := &simulator{
+		start:                     time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		newCardsPerDay:            20,
+		learnCost:                 20 * time.Second,
+		reviewCost:                8 * time.Second,
+		relearnCost:               25 * time.Second,
+		firstRatingProbabilities:  [4]float64{0.24, 0.094, 0.495, 0.171},
+		reviewRatingProbabilities: [3]float64{0.224, 0.631, 0.145},
+	}
+
+	for _, option := range options {
+		if err := option(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if days < 0 {
+		return nil, fmt.Errorf("%w days must not be negative, got %d", ErrInvalidOption, days)
+	}
+
+	return s.run(scheduler, days), nil
+}
+
+func (s *simulator) run(scheduler *fsrs.Scheduler, days int) []DayStats {
+	var (
+		stats   = make([]DayStats, days)
+		learned []*fsrs.Card
+		queue   cardQueue
+	)
+
+	for day := range stats {
+		var (
+			dayStart  = s.start.Add(time.Duration(day) * 24 * time.Hour)
+			dayEnd    = dayStart.Add(24 * time.Hour)
+			today     = &stats[day]
+			reviews   int
+			postponed []*fsrs.Card
+		)
+		today.Start = dayStart
+
+		for i := 0; i < s.newCardsPerDay && (s.deckSize == 0 || len(learned) < s.deckSize); i++ {
+			card := fsrs.NewEmptyCard(int64(len(learned) + 1))
+			card.Due = dayStart
+			learned = append(learned, card)
+			heap.Push(&queue, card)
+		}
+
+		for queue.Len() > 0 && queue[0].Due.Before(dayEnd) {
+			card := heap.Pop(&queue).(*fsrs.Card)
+
+			if card.State == fsrs.Review && s.reviewLimit > 0 && reviews >= s.reviewLimit {
+				postponed = append(postponed, card)
+				continue
+			}
+
+			now := card.Due
+			if now.Before(dayStart) {
+				now = dayStart
+			}
+
+			var rating fsrs.Rating
+			switch {
+			case card.LastReview == nil:
+				rating = fsrs.Rating(1 + s.sample(s.firstRatingProbabilities[:]))
+				today.NewCards++
+				today.TimeSpent += s.learnCost
+			case s.float64() < scheduler.GetCardRetrievability(card, now):
+				rating = fsrs.Rating(2 + s.sample(s.reviewRatingProbabilities[:]))
+				today.Reviews++
+				today.TimeSpent += s.reviewCost
+			default:
+				rating = fsrs.Again
+				today.Reviews++
+				today.TimeSpent += s.relearnCost
+				if card.State == fsrs.Review {
+					today.Lapses++
+				}
+			}
+
+			if card.State == fsrs.Review {
+				reviews++
+			}
+
+			reviewed := scheduler.ReviewCard(card, rating, now)
+			*card = *reviewed
+			heap.Push(&queue, card)
+		}
+
+		for _, card := range postponed {
+			heap.Push(&queue, card)
+		}
+
+		for _, card := range learned {
+			today.Memorized += scheduler.GetCardRetrievability(card, dayEnd)
+		}
+	}
+
+	return stats
+}
+
+func (s *simulator) float64() float64 {
+	if s.rand == nil {
+		return rand.Float64()
+	}
+	return s.rand.Float64()
+}
+
+// sample returns an index drawn with the given relative probabilities.
+func (s *simulator) sample(probabilities []float64) int {
+	var sum float64
+	for _, p := range probabilities {
+		sum += p
+	}
+
+	r := s.float64() * sum
+	for i, p := range probabilities {
+		if r < p {
+			return i
+		}
+		r -= p
+	}
+
+	return len(probabilities) - 1
+}
+
+// cardQueue is a heap of cards ordered by due date and card ID.
+type cardQueue []*fsrs.Card
+
+func (q cardQueue) Len() int { return len(q) }
+
+func (q cardQueue) Less(i, j int) bool {
+	if !q[i].Due.Equal(q[j].Due) {
+		return q[i].Due.Before(q[j].Due)
+	}
+	return q[i].ID < q[j].ID
+}
+
+func (q cardQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *cardQueue) Push(x any) { *q = append(*q, x.(*fsrs.Card)) }
+
+func (q *cardQueue) Pop() any {
+	old := *q
+	card := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return card
+}
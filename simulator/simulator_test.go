@@ -0,0 +1,85 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/patricksuo/fsrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustNewScheduler(options ...fsrs.SchedulerOption) *fsrs.Scheduler {
+	s, err := fsrs.NewScheduler(options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+func TestSimulateReproducible(t *testing.T) {
+	run := func() []DayStats {
+		scheduler := mustNewScheduler(fsrs.WithRandomSource(rand.NewSource(1)))
+		stats, err := Simulate(scheduler, 60, WithRandomSource(rand.NewSource(2)), WithNewCardsPerDay(10))
+		assert.NoError(t, err)
+		return stats
+	}
+
+	first := run()
+	assert.Len(t, first, 60)
+	assert.Equal(t, first, run())
+
+	var previous float64
+	for _, day := range first {
+		assert.Equal(t, 10, day.NewCards)
+		assert.Greater(t, day.TimeSpent, time.Duration(0))
+		assert.Greater(t, day.Memorized, previous)
+		previous = day.Memorized
+	}
+}
+
+func TestSimulateLimits(t *testing.T) {
+	// without learning steps every review after the first one is a Review-state review
+	scheduler := mustNewScheduler(
+		fsrs.WithEnableFuzzing(false),
+		fsrs.WithLearningSteps(nil),
+		fsrs.WithRelearningSteps(nil),
+	)
+
+	stats, err := Simulate(scheduler, 90,
+		WithRandomSource(rand.NewSource(3)),
+		WithDeckSize(100),
+		WithNewCardsPerDay(30),
+		WithReviewLimit(5),
+	)
+	assert.NoError(t, err)
+
+	var learned, limited int
+	for _, day := range stats {
+		learned += day.NewCards
+		assert.LessOrEqual(t, day.NewCards, 30)
+		assert.LessOrEqual(t, day.Reviews, 5)
+		if day.Reviews == 5 {
+			limited++
+		}
+	}
+	assert.Equal(t, 100, learned)
+	assert.Greater(t, limited, 0)
+}
+
+func TestSimulateInvalidOptions(t *testing.T) {
+	scheduler := mustNewScheduler()
+
+	_, err := Simulate(scheduler, 10, WithNewCardsPerDay(-1))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = Simulate(scheduler, 10, WithReviewRatingProbabilities([3]float64{}))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = Simulate(scheduler, 10, WithRandomSource(nil))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = Simulate(scheduler, -1)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
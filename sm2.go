@@ -0,0 +1,54 @@
+package fsrs
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// MemoryStateFromSM2 estimates the stability and difficulty of a card scheduled by SM-2 with
+// easeFactor (2.5 for 250%) and interval in days, assuming SM-2 reviewed it at sm2Retention.
+//
+// The stability is the one at which the card reaches sm2Retention after interval days, and the
+// difficulty is the one at which a successful review grows the stability by easeFactor, the
+// same as memory_state_from_sm2 of fsrs-rs.
+func (s *Scheduler) MemoryStateFromSM2(easeFactor, interval, sm2Retention float64) (stability, difficulty float64, err error) {
+	if !(easeFactor > 0) || math.IsInf(easeFactor, 1) {
+		return 0, 0, fmt.Errorf("%w ease factor = %f must be positive", ErrInvalidSM2, easeFactor)
+	}
+	if !(interval > 0) || math.IsInf(interval, 1) {
+		return 0, 0, fmt.Errorf("%w interval = %f must be positive", ErrInvalidSM2, interval)
+	}
+	if !(sm2Retention > 0 && sm2Retention < 1) {
+		return 0, 0, fmt.Errorf("%w retention = %f is out of bounds: (0, 1)", ErrInvalidSM2, sm2Retention)
+	}
+
+	p := &s.parameters
+
+	stability = max(interval, StabilityMin) * s.factor / (math.Pow(sm2Retention, 1/s.decay) - 1)
+	difficulty = 11 - (easeFactor-1)/
+		(math.Exp(p.RecallStabilityScale)*
+			math.Pow(stability, -p.RecallStabilityDecay)*
+			math.Expm1((1-sm2Retention)*p.RecallRetrievabilityFactor))
+
+	return min(max(stability, StabilityMin), StabilityMax), s.clampDdifficulty(difficulty), nil
+}
+
+// CardFromSM2 returns a Review-state card with the memory state estimated by MemoryStateFromSM2,
+// last reviewed at lastReview and due interval days later like in SM-2.
+func (s *Scheduler) CardFromSM2(id int64, easeFactor, interval float64, lastReview time.Time, sm2Retention float64) (*Card, error) {
+	stability, difficulty, err := s.MemoryStateFromSM2(easeFactor, interval, sm2Retention)
+	if err != nil {
+		return nil, fmt.Errorf("card %d: %w", id, err)
+	}
+
+	return &Card{
+		ID:         id,
+		State:      Review,
+		Step:       -1,
+		Stability:  stability,
+		Difficulty: difficulty,
+		Due:        s.addDays(lastReview, max(int(math.Round(interval)), 1)),
+		LastReview: &lastReview,
+	}, nil
+}
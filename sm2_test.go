@@ -0,0 +1,59 @@
+package fsrs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStateFromSM2(t *testing.T) {
+	scheduler := mustNewScheduler(WithEnableFuzzing(false))
+
+	// at the desired retention of 0.9 the stability is the interval
+	stability, difficulty, err := scheduler.MemoryStateFromSM2(2.5, 10, 0.9)
+	assert.NoError(t, err)
+	assert.InDelta(t, 10, stability, 1e-9)
+
+	// a successful review at the SM-2 retention grows the stability by the ease factor
+	assert.InDelta(t, 25, scheduler.nextStability(difficulty, stability, 0.9, Good), 1e-9)
+
+	// harder cards get a higher difficulty
+	_, harder, err := scheduler.MemoryStateFromSM2(1.3, 10, 0.9)
+	assert.NoError(t, err)
+	assert.Greater(t, harder, difficulty)
+
+	// a card forgotten more often by the end of the interval is less stable
+	stability, _, err = scheduler.MemoryStateFromSM2(2.5, 10, 0.8)
+	assert.NoError(t, err)
+	assert.Less(t, stability, 10.0)
+
+	for _, tc := range []struct{ ease, interval, retention float64 }{
+		{0, 10, 0.9},
+		{2.5, 0, 0.9},
+		{2.5, 10, 1},
+	} {
+		_, _, err = scheduler.MemoryStateFromSM2(tc.ease, tc.interval, tc.retention)
+		assert.ErrorIs(t, err, ErrInvalidSM2)
+	}
+}
+
+func TestCardFromSM2(t *testing.T) {
+	scheduler := mustNewScheduler(WithEnableFuzzing(false))
+	lastReview := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+
+	card, err := scheduler.CardFromSM2(1, 2.5, 10, lastReview, 0.9)
+	assert.NoError(t, err)
+	assert.Equal(t, Review, card.State)
+	assert.Equal(t, lastReview, *card.LastReview)
+	assert.Equal(t, lastReview.AddDate(0, 0, 10), card.Due)
+
+	// the card is reviewed as a Review card
+	reviewed := scheduler.ReviewCard(card, Good, card.Due)
+	assert.Equal(t, Review, reviewed.State)
+	assert.InDelta(t, 25, reviewed.Stability, 1e-9)
+	assert.Equal(t, 25.0, reviewed.Due.Sub(card.Due).Hours()/24)
+
+	_, err = scheduler.CardFromSM2(1, 2.5, -1, lastReview, 0.9)
+	assert.ErrorIs(t, err, ErrInvalidSM2)
+}
@@ -0,0 +1,205 @@
+package fsrs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SchedulerSnapshotVersion is the format version of the snapshots created by Scheduler.Snapshot.
+const SchedulerSnapshotVersion = 1
+
+// SchedulerSnapshot is the configuration of a Scheduler.
+//
+// It marshals to JSON with durations written as strings such as "10m0s".
+type SchedulerSnapshot struct {
+	// Version is the format version of the snapshot, 0 is read as version 1.
+	Version int `json:"version"`
+
+	// Parameters are the model weights of the FSRS scheduler.
+	Parameters []float64 `json:"parameters"`
+
+	// DesiredRetention is the desired retention rate of cards scheduled with the scheduler.
+	DesiredRetention float64 `json:"desired_retention"`
+
+	// LearningSteps are small time intervals that schedule cards in the Learning state.
+	LearningSteps []time.Duration `json:"learning_steps"`
+
+	// RelearningSteps are small time intervals that schedule cards in the Relearning state.
+	RelearningSteps []time.Duration `json:"relearning_steps"`
+
+	// MaximumInterval is the maximum number of days a Review-state card can be scheduled into the future.
+	MaximumInterval int `json:"maximum_interval"`
+
+	// EnableFuzzing determines whether to apply a small amount of random 'fuzz' to calculated intervals.
+	EnableFuzzing bool `json:"enable_fuzzing"`
+
+	// DeterministicFuzz determines whether to derive fuzz from the card instead of a random source.
+	DeterministicFuzz bool `json:"deterministic_fuzz"`
+
+	// FuzzConfig describes how much intervals are fuzzed, nil for DefaultFuzzConfig.
+	FuzzConfig *FuzzConfig `json:"fuzz_config,omitempty"`
+
+	// EasyDays is the relative workload of each time.Weekday, empty when easy days are disabled.
+	EasyDays []float64 `json:"easy_days,omitempty"`
+
+	// Location is the IANA name of the time zone of the scheduler, or the name of a fixed zone, empty for the time zone of each review.
+	Location string `json:"location,omitempty"`
+
+	// LocationOffset is the UTC offset in seconds of a fixed time zone named Location, nil for IANA time zones.
+	LocationOffset *int `json:"location_offset,omitempty"`
+
+	// LeechThreshold is the number of lapses at which a card becomes a leech, 0 when leech detection is disabled.
+	LeechThreshold int `json:"leech_threshold,omitempty"`
+
+	// CalendarDays determines whether days start at DayBoundary instead of every 24 hours from a review.
+	CalendarDays bool `json:"calendar_days,omitempty"`
+
+	// DayBoundary is the hour at which a new day starts when CalendarDays is set.
+	DayBoundary int `json:"day_boundary,omitempty"`
+}
+
+// Snapshot dump a scheduler snapshot
+func (s *Scheduler) Snapshot() *SchedulerSnapshot {
+	ss := &SchedulerSnapshot{Version: SchedulerSnapshotVersion}
+
+	ss.Parameters = s.parameters.Vector()
+	ss.DesiredRetention = s.desiredRetention
+	ss.LearningSteps = append(ss.LearningSteps, s.learningSteps...)
+	ss.RelearningSteps = append(ss.RelearningSteps, s.relearningSteps...)
+	ss.MaximumInterval = s.maximumInterval
+	ss.EnableFuzzing = s.enableFuzzing
+	ss.DeterministicFuzz = s.deterministicFuzz
+	fuzz := s.fuzz.clone()
+	ss.FuzzConfig = &fuzz
+	ss.EasyDays = append(ss.EasyDays, s.easyDays...)
+	if s.location != nil {
+		ss.Location = s.location.String()
+		if _, err := time.LoadLocation(ss.Location); err != nil {
+			offset, _ := zoneOffset(s.location)
+			ss.LocationOffset = &offset
+		}
+	}
+	ss.LeechThreshold = s.leechThreshold
+	ss.CalendarDays = s.calendarDays
+	ss.DayBoundary = s.dayBoundary
+
+	return ss
+}
+
+// NewSchedulerFromSnapshot creates a Scheduler with the configuration of ss, then applies options.
+func NewSchedulerFromSnapshot(ss *SchedulerSnapshot, options ...SchedulerOption) (*Scheduler, error) {
+	if ss.Version < 0 || ss.Version > SchedulerSnapshotVersion {
+		return nil, fmt.Errorf("%w version %d, expected at most %d", ErrInvalidSnapshot, ss.Version, SchedulerSnapshotVersion)
+	}
+
+	snapshotOptions := []SchedulerOption{
+		WithParameters(append([]float64(nil), ss.Parameters...)),
+		WithDesiredRetention(ss.DesiredRetention),
+		WithLearningSteps(append([]time.Duration(nil), ss.LearningSteps...)),
+		WithRelearningSteps(append([]time.Duration(nil), ss.RelearningSteps...)),
+		WithMaximumInterval(ss.MaximumInterval),
+		WithEnableFuzzing(ss.EnableFuzzing),
+		WithDeterministicFuzz(ss.DeterministicFuzz),
+		WithLeechThreshold(ss.LeechThreshold),
+	}
+
+	if ss.FuzzConfig != nil {
+		snapshotOptions = append(snapshotOptions, WithFuzzConfig(*ss.FuzzConfig))
+	}
+
+	if len(ss.EasyDays) > 0 {
+		var workloads [7]float64
+		if len(ss.EasyDays) != len(workloads) {
+			return nil, fmt.Errorf("%w easy days has %d workloads, expected %d", ErrInvalidSnapshot, len(ss.EasyDays), len(workloads))
+		}
+		copy(workloads[:], ss.EasyDays)
+		snapshotOptions = append(snapshotOptions, WithEasyDays(workloads))
+	}
+
+	if ss.LocationOffset != nil {
+		snapshotOptions = append(snapshotOptions, WithLocation(time.FixedZone(ss.Location, *ss.LocationOffset)))
+	} else if ss.Location != "" {
+		location, err := time.LoadLocation(ss.Location)
+		if err != nil {
+			return nil, fmt.Errorf("%w location: %w", ErrInvalidSnapshot, err)
+		}
+		snapshotOptions = append(snapshotOptions, WithLocation(location))
+	}
+
+	if ss.CalendarDays {
+		snapshotOptions = append(snapshotOptions, WithDayBoundary(ss.DayBoundary))
+	}
+
+	return NewScheduler(append(snapshotOptions, options...)...)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (ss SchedulerSnapshot) MarshalJSON() ([]byte, error) {
+	type snapshot SchedulerSnapshot
+
+	return json.Marshal(struct {
+		snapshot
+		LearningSteps   []string `json:"learning_steps"`
+		RelearningSteps []string `json:"relearning_steps"`
+	}{
+		snapshot:        snapshot(ss),
+		LearningSteps:   formatDurations(ss.LearningSteps),
+		RelearningSteps: formatDurations(ss.RelearningSteps),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (ss *SchedulerSnapshot) UnmarshalJSON(data []byte) error {
+	type snapshot SchedulerSnapshot
+
+	aux := struct {
+		*snapshot
+		LearningSteps   []string `json:"learning_steps"`
+		RelearningSteps []string `json:"relearning_steps"`
+	}{
+		snapshot: (*snapshot)(ss),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	var err error
+	if ss.LearningSteps, err = parseDurations(aux.LearningSteps); err != nil {
+		return fmt.Errorf("%w learning steps: %w", ErrInvalidSnapshot, err)
+	}
+	if ss.RelearningSteps, err = parseDurations(aux.RelearningSteps); err != nil {
+		return fmt.Errorf("%w relearning steps: %w", ErrInvalidSnapshot, err)
+	}
+
+	return nil
+}
+
+func formatDurations(durations []time.Duration) []string {
+	if durations == nil {
+		return nil
+	}
+
+	formatted := make([]string, len(durations))
+	for i, d := range durations {
+		formatted[i] = d.String()
+	}
+	return formatted
+}
+
+func parseDurations(formatted []string) ([]time.Duration, error) {
+	if formatted == nil {
+		return nil, nil
+	}
+
+	durations := make([]time.Duration, len(formatted))
+	for i, f := range formatted {
+		d, err := time.ParseDuration(f)
+		if err != nil {
+			return nil, err
+		}
+		durations[i] = d
+	}
+	return durations, nil
+}
@@ -0,0 +1,71 @@
+package fsrs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSchedulerFromSnapshot(t *testing.T) {
+	parameters := append([]float64(nil), DefaultParameters...)
+	parameters[0] = 0.3
+
+	scheduler := mustNewScheduler(
+		WithParameters(parameters),
+		WithDesiredRetention(0.85),
+		WithLearningSteps([]time.Duration{30 * time.Second, 15 * time.Minute, 2 * time.Hour}),
+		WithRelearningSteps(nil),
+		WithMaximumInterval(3650),
+		WithEnableFuzzing(false),
+	)
+
+	restored, err := NewSchedulerFromSnapshot(scheduler.Snapshot())
+	assert.NoError(t, err)
+	assert.Equal(t, scheduler.Snapshot(), restored.Snapshot())
+	assert.Equal(t, scheduler.decay, restored.decay)
+	assert.Equal(t, scheduler.factor, restored.factor)
+
+	// options override the snapshot
+	restored, err = NewSchedulerFromSnapshot(scheduler.Snapshot(), WithDesiredRetention(0.8))
+	assert.NoError(t, err)
+	assert.Equal(t, 0.8, restored.desiredRetention)
+
+	ss := scheduler.Snapshot()
+	ss.DesiredRetention = 2
+	_, err = NewSchedulerFromSnapshot(ss)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	ss = scheduler.Snapshot()
+	ss.Version = SchedulerSnapshotVersion + 1
+	_, err = NewSchedulerFromSnapshot(ss)
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+}
+
+func TestSchedulerSnapshotJSON(t *testing.T) {
+	scheduler := mustNewScheduler(WithRelearningSteps(nil))
+
+	data, err := json.Marshal(scheduler.Snapshot())
+	assert.NoError(t, err)
+
+	var fields map[string]any
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, float64(SchedulerSnapshotVersion), fields["version"])
+	assert.Equal(t, []any{"1m0s", "10m0s"}, fields["learning_steps"])
+	assert.Nil(t, fields["relearning_steps"])
+	assert.Equal(t, 0.9, fields["desired_retention"])
+	assert.Equal(t, float64(36500), fields["maximum_interval"])
+	assert.Equal(t, true, fields["enable_fuzzing"])
+
+	var ss SchedulerSnapshot
+	assert.NoError(t, json.Unmarshal(data, &ss))
+	assert.Equal(t, scheduler.Snapshot(), &ss)
+
+	restored, err := NewSchedulerFromSnapshot(&ss)
+	assert.NoError(t, err)
+	assert.Equal(t, scheduler.Snapshot(), restored.Snapshot())
+
+	err = json.Unmarshal([]byte(`{"learning_steps": ["ten minutes"]}`), &ss)
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+}